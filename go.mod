@@ -4,7 +4,8 @@ go 1.16
 
 require (
 	github.com/aws/aws-lambda-go v1.22.0
+	github.com/aws/aws-sdk-go v1.37.25
 	github.com/russross/blackfriday/v2 v2.1.0 // indirect
-	github.com/stretchr/testify v1.6.1 // indirect
-	github.com/urfave/cli/v2 v2.3.0 // indirect
+	github.com/stretchr/testify v1.6.1
+	github.com/urfave/cli/v2 v2.3.0
 )
@@ -1,38 +0,0 @@
-package dto
-
-type GeneratedFact struct {
-	ID        string `json:"id"`
-	Text      string `json:"text"`
-	Source    string `json:"source"`
-	SourceURL string `json:"source_url"`
-	Language  string `json:"language"`
-	Permalink string `json:"permalink"`
-}
-
-type JokeValue struct {
-	ID         int      `json:"id"`
-	Joke       string   `json:"joke"`
-	Categories []string `json:"categories"`
-}
-
-type GeneratedJoke struct {
-	Type  string `json:"type"`
-	Value JokeValue
-}
-
-// Update is a Telegram object that the handler receives every time an user interacts with the bot.
-type Update struct {
-	UpdateId int     `json:"update_id"`
-	Message  Message `json:"message"`
-}
-
-// Message is a Telegram object that can be found in an update.
-type Message struct {
-	Text string `json:"text"`
-	Chat Chat   `json:"chat"`
-}
-
-// A Telegram Chat indicates the conversation to which the message belongs.
-type Chat struct {
-	Id int `json:"id"`
-}
@@ -0,0 +1,580 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"my-first-telegram-bot/telegram-handler/commands"
+	"my-first-telegram-bot/telegram-handler/dedup"
+	"my-first-telegram-bot/telegram-handler/dto"
+	"my-first-telegram-bot/telegram-handler/history"
+	"my-first-telegram-bot/telegram-handler/restclient"
+	"my-first-telegram-bot/telegram-handler/settings"
+	"os"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrorHttpRequest         = "Error executing http request"
+	InformalInvalidResponse  = "Thank you for reaching out, stuff is up and running, but this is a telegram bot and this endpoint will eventually vanish"
+	InvalidInputFromTelegram = "No valid input from telegram request detected"
+	TryAgainReply            = "Sorry, that took too long. Please try again in a moment."
+	UnavailableReply         = "Sorry, I can't reach my sources right now. Please try again in a little while."
+	FailureReply             = "Sorry, something went wrong on my side. Please try again later."
+	DuplicateUpdate          = "Update already handled"
+	UnaddressedCommand       = "Unknown command not addressed to this bot"
+	Unauthorized             = "Unauthorized"
+
+	// DefaultReplyBudget is the time kept aside before the Lambda deadline to tell the chat something went wrong.
+	DefaultReplyBudget = time.Second
+
+	// DefaultSeenUpdatesTTL is how long handled update ids are remembered. Telegram stops
+	// redelivering an update well before that.
+	DefaultSeenUpdatesTTL = 24 * time.Hour
+)
+
+// Config holds the settings that identify a bot instance.
+type Config struct {
+	TelegramApiToken string
+	// TelegramApiAddress is the address of the Telegram API, followed by the bot prefix.
+	// Empty means restclient.DefaultTelegramApiAddress.
+	TelegramApiAddress string
+	// BotUsername is the username of the bot. Empty means unknown until
+	// LookUpUsernameOnStart asks Telegram for it.
+	BotUsername string
+	// ReplyBudget is subtracted from the invocation deadline to get the deadline of the commands.
+	ReplyBudget time.Duration
+	// SeenUpdatesFile, when set, keeps the handled update ids in that file instead of in memory.
+	SeenUpdatesFile string
+	// WebhookSecretToken, when set, must match the secret token header of every request.
+	WebhookSecretToken string
+	// WebhookPathToken, when set, must match the last segment of the path of every request.
+	WebhookPathToken string
+	// JokeSource is the name of the joke source, one of restclient.JokeSourceNames().
+	JokeSource string
+	// SyncCommandsOnStart publishes the command menus when the bot starts.
+	SyncCommandsOnStart bool
+	// EventFormat is the format of the events the Lambda function is invoked with, one of
+	// EventFormatAuto, EventFormatRestApi or EventFormatHttpApi.
+	EventFormat string
+	// PunchlineDelivery is how the punchline of two part jokes is sent, either
+	// PunchlineDeliveryDelay, the default, or PunchlineDeliveryButton.
+	PunchlineDelivery string
+	// PunchlineDelay is how long the punchline waits with PunchlineDeliveryDelay.
+	// Zero means DefaultPunchlineDelay.
+	PunchlineDelay time.Duration
+	// ChatSettingsTable, when set, keeps the chat settings in that DynamoDB table.
+	ChatSettingsTable string
+	// DynamoDBEndpoint, when set, points the DynamoDB client elsewhere, like to DynamoDB Local.
+	DynamoDBEndpoint string
+	// ChatSettingsFile, when set and no table is, keeps the chat settings in that file
+	// instead of in memory.
+	ChatSettingsFile string
+}
+
+// ConfigFromEnv reads the bot configuration from the environment variables set on the Lambda function.
+func ConfigFromEnv() Config {
+
+	config := Config{
+		TelegramApiToken:   os.Getenv("TELEGRAM_API_TOKEN"),
+		TelegramApiAddress: os.Getenv("TELEGRAM_API_ADDRESS"),
+		BotUsername:        os.Getenv("TELEGRAM_BOT_USERNAME"),
+		ReplyBudget:        DefaultReplyBudget,
+		SeenUpdatesFile:    os.Getenv("SEEN_UPDATES_FILE"),
+		WebhookSecretToken: os.Getenv("TELEGRAM_WEBHOOK_SECRET_TOKEN"),
+		WebhookPathToken:   os.Getenv("TELEGRAM_WEBHOOK_PATH_TOKEN"),
+		EventFormat:        os.Getenv("LAMBDA_EVENT_FORMAT"),
+		JokeSource:         os.Getenv("JOKE_SOURCE"),
+		PunchlineDelivery:  os.Getenv("PUNCHLINE_DELIVERY"),
+		ChatSettingsTable:  os.Getenv("CHAT_SETTINGS_TABLE"),
+		DynamoDBEndpoint:   os.Getenv("DYNAMODB_ENDPOINT"),
+		ChatSettingsFile:   os.Getenv("CHAT_SETTINGS_FILE"),
+	}
+
+	if config.TelegramApiAddress == "" {
+		config.TelegramApiAddress = restclient.DefaultTelegramApiAddress
+	}
+
+	config.SyncCommandsOnStart, _ = strconv.ParseBool(os.Getenv("SYNC_COMMANDS_ON_START"))
+
+	if punchlineDelay, err := time.ParseDuration(os.Getenv("PUNCHLINE_DELAY")); err == nil {
+		config.PunchlineDelay = punchlineDelay
+	}
+
+	return config
+}
+
+// Bot handles the Telegram updates of a single bot using the clients it was constructed with.
+type Bot struct {
+	FactClient     restclient.FactClient
+	JokeClient     restclient.JokeClient
+	TelegramClient restclient.TelegramClient
+	Config         Config
+	DeadLetters    DeadLetterSink
+	SeenUpdates    dedup.Store
+	// CommandsClient publishes the command menus, see SyncCommands.
+	CommandsClient restclient.CommandsClient
+	// CallbackClient answers the presses of the buttons showing punchlines.
+	CallbackClient restclient.CallbackClient
+	// IdentityClient looks up the username of the bot, see LookUpUsernameOnStart.
+	IdentityClient restclient.IdentityClient
+	ChatSettings   settings.ChatSettingsStore
+	// History remembers the facts and jokes delivered to each chat, so they aren't repeated.
+	History history.Store
+	// Clock times the punchline delay.
+	Clock restclient.Clock
+
+	registry *commands.Registry
+}
+
+func NewBot(factClient restclient.FactClient, jokeClient restclient.JokeClient, telegramClient restclient.TelegramClient, config Config) *Bot {
+
+	bot := &Bot{
+		FactClient:     factClient,
+		JokeClient:     jokeClient,
+		TelegramClient: telegramClient,
+		Config:         config,
+		DeadLetters:    LogDeadLetterSink{},
+		SeenUpdates:    newSeenUpdatesStore(config),
+		ChatSettings:   newLocalChatSettingsStore(config),
+		History:        history.NewMemoryStore(DefaultHistorySize, DefaultHistoryTTL),
+		Clock:          restclient.SystemClock,
+		registry:       commands.NewRegistry(),
+	}
+
+	bot.registerCommands()
+
+	return bot
+}
+
+// NewBotFromConfig creates a bot talking to the real fact, joke and Telegram APIs,
+// keeping the chat settings in the configured table, file or memory.
+func NewBotFromConfig(config Config) (*Bot, error) {
+
+	chatSettings, err := newChatSettingsStore(config)
+
+	if err != nil {
+		return nil, err
+	}
+
+	jokeClient, err := newJokeClient(config.JokeSource)
+
+	if err != nil {
+		return nil, err
+	}
+
+	bot := NewBot(
+		restclient.NewFactClient(),
+		jokeClient,
+		restclient.NewThrottledTelegramClient(
+			restclient.NewTelegramClient(config.TelegramApiAddress, config.TelegramApiToken),
+			restclient.NewRateLimiter(
+				restclient.TelegramGlobalMessagesPerSecond,
+				restclient.TelegramChatMessagesPerSecond,
+				restclient.SystemClock)),
+		config)
+
+	telegramApiClient := restclient.NewTelegramApiClient(config.TelegramApiAddress, config.TelegramApiToken)
+
+	bot.CommandsClient = telegramApiClient
+	bot.CallbackClient = telegramApiClient
+	bot.IdentityClient = telegramApiClient
+	bot.ChatSettings = chatSettings
+
+	return bot, nil
+}
+
+// newJokeClient creates the client of the configured joke source, or of the default
+// one when none is configured.
+func newJokeClient(source string) (restclient.JokeClient, error) {
+
+	if source == "" {
+		source = restclient.DefaultJokeSource
+	}
+
+	jokeClient, err := restclient.NewJokeClient(source)
+
+	if err != nil {
+		return nil, fmt.Errorf("picking the joke source: %w", err)
+	}
+
+	return jokeClient, nil
+}
+
+func newSeenUpdatesStore(config Config) dedup.Store {
+
+	if config.SeenUpdatesFile != "" {
+		return dedup.NewFileStore(config.SeenUpdatesFile, DefaultSeenUpdatesTTL)
+	}
+
+	return dedup.NewMemoryStore(DefaultSeenUpdatesTTL)
+}
+
+// Response is the answer to a webhook request, whatever transport it came through.
+type Response struct {
+	StatusCode int
+	Headers    map[string]string
+	Body       string
+}
+
+// WebhookRequest is a webhook request, whatever transport it came through.
+type WebhookRequest struct {
+	Path string
+	// Headers are looked up regardless of the case of their names.
+	Headers map[string]string
+	Body    string
+}
+
+// HandleWebhook processes a Telegram webhook request. Commands run against the
+// context deadline minus the reply budget, so there is still time to ask the
+// chat to try again when they don't finish in time.
+//
+// Every update is acknowledged with a 200 once accepted, so Telegram never
+// redelivers it: failures are answered with a fallback reply and recorded as
+// dead letters instead. Each update id is handled at most once: redeliveries
+// are acknowledged without being processed again. Requests failing the webhook
+// token checks are rejected with a 401 before anything else.
+func (b *Bot) HandleWebhook(ctx context.Context, request *WebhookRequest) *Response {
+
+	if err := b.verifyWebhookRequest(request); err != nil {
+		log.Printf("Rejected webhook request: %v", err)
+
+		return &Response{
+			StatusCode: 401,
+			Body:       Unauthorized,
+		}
+	}
+
+	return b.process(ctx, request.Body, true)
+}
+
+// ProcessUpdate processes the JSON encoded update like HandleWebhook does, except
+// that replies are always sent through the Telegram client. It suits updates that
+// didn't come from a webhook, like the ones fetched with getUpdates.
+func (b *Bot) ProcessUpdate(ctx context.Context, body string) *Response {
+
+	return b.process(ctx, body, false)
+}
+
+func (b *Bot) process(ctx context.Context, body string, allowWebhookReply bool) (response *Response) {
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			log.Printf("Recovered from panic while handling the update: %v", recovered)
+
+			b.recordDeadLetter(&DeadLetter{
+				Stage: DeadLetterStagePanic,
+				Error: fmt.Sprint(recovered),
+				Body:  body,
+			})
+
+			response = &Response{
+				StatusCode: 200,
+				Body:       ErrorHttpRequest,
+			}
+		}
+	}()
+
+	log.Printf("The request has the following body: %s", body)
+
+	botRequest, err := parseTelegramRequest(body)
+
+	if err != nil {
+		return &Response{
+			StatusCode: 200,
+			Body:       InformalInvalidResponse,
+		}
+	}
+
+	if !b.markSeen(botRequest.Update.UpdateId) {
+		log.Printf("Update %d was already handled", botRequest.Update.UpdateId)
+
+		return &Response{
+			StatusCode: 200,
+			Body:       DuplicateUpdate,
+		}
+	}
+
+	if botRequest.Update.CallbackQuery != nil {
+		return b.handleCallbackQuery(ctx, botRequest, body)
+	}
+
+	message := botRequest.Update.CommandMessage()
+
+	if message == nil {
+		log.Printf(InvalidInputFromTelegram)
+
+		return &Response{
+			StatusCode: 200,
+			Body:       InvalidInputFromTelegram,
+		}
+	}
+
+	invocation, ok := commands.ParseMessage(message)
+
+	if !ok || !invocation.IsAddressedTo(b.Config.BotUsername) {
+		log.Printf(InvalidInputFromTelegram)
+
+		return &Response{
+			StatusCode: 200,
+			Body:       InvalidInputFromTelegram,
+		}
+	}
+
+	command, found := b.registry.Lookup(invocation.Name)
+
+	// Other bots of a group get the commands meant for them too, so unknown ones are
+	// only answered when they are explicitly addressed to this bot.
+	if !found && message.Chat.IsGroup() && !invocation.IsExplicitlyAddressedTo(b.Config.BotUsername) {
+		log.Printf("Ignoring /%s in group chat %d, it may be meant for another bot", invocation.Name, botRequest.ChatId)
+
+		return &Response{
+			StatusCode: 200,
+			Body:       UnaddressedCommand,
+		}
+	}
+
+	botRequest.Invocation = invocation
+
+	log.Printf("Handling /%s from %s in chat %d", invocation.Name, botRequest.Sender(), botRequest.ChatId)
+
+	var (
+		reply         *commands.Reply
+		commandFailed bool
+		webhookReply  bool
+		sentItem      string
+	)
+
+	if found {
+
+		webhookReply = command.WebhookReply && allowWebhookReply
+
+		commandCtx, cancel := b.commandContext(ctx)
+
+		commandCtx = settings.NewContext(commandCtx, b.chatSettings(commandCtx, botRequest.ChatId))
+
+		reply, err = b.runCommand(commandCtx, command, botRequest)
+
+		cancel()
+
+		if err != nil {
+			log.Printf("/%s failed: %v", invocation.Name, err)
+
+			b.recordDeadLetter(newDeadLetter(botRequest, body, DeadLetterStageCommand, err))
+
+			reply = fallbackReply(ctx, err)
+			commandFailed = true
+		} else {
+			sentItem = historyKey(command, reply)
+		}
+
+	} else {
+		log.Printf("Unknown command /%s", invocation.Name)
+
+		reply = b.unknownCommandReply(invocation.Name)
+	}
+
+	reply, followUp := b.splitFollowUp(command, reply)
+
+	sendMessage := newSendMessageRequest(botRequest, message, reply)
+
+	if webhookReply && followUp == nil {
+		b.rememberSent(ctx, botRequest.ChatId, sentItem)
+
+		return webhookSendMessageResponse(sendMessage)
+	}
+
+	sentMessage, err := b.TelegramClient.SendMessageWithContext(ctx, sendMessage)
+
+	if err != nil {
+		log.Printf("Failed to send the reply to chat %d: %v", botRequest.ChatId, err)
+
+		b.recordDeadLetter(newDeadLetter(botRequest, body, DeadLetterStageReply, err))
+
+		return &Response{
+			StatusCode: 200,
+			Body:       err.Error(),
+		}
+	}
+
+	log.Printf("Sent message %d to chat %d", sentMessage.MessageId, sentMessage.Chat.Id)
+
+	b.rememberSent(ctx, botRequest.ChatId, sentItem)
+
+	if followUp != nil {
+		b.sendFollowUp(ctx, botRequest, message, body, followUp)
+	}
+
+	if commandFailed {
+		return &Response{
+			StatusCode: 200,
+			Body:       ErrorHttpRequest,
+		}
+	}
+
+	return sentMessageResponse(sentMessage)
+}
+
+// sentMessageResponse acknowledges the update with the message sent in reply to it.
+func sentMessageResponse(sentMessage *dto.Message) *Response {
+
+	responseBody, err := json.Marshal(sentMessage)
+
+	if err != nil {
+		return &Response{
+			StatusCode: 200,
+		}
+	}
+
+	return &Response{
+		StatusCode: 200,
+		Body:       string(responseBody),
+	}
+}
+
+// webhookSendMessageResponse answers the update with a sendMessage call in the response body.
+func webhookSendMessageResponse(sendMessage *dto.SendMessageRequest) *Response {
+
+	responseBody, err := json.Marshal(dto.NewWebhookSendMessage(sendMessage))
+
+	if err != nil {
+		return &Response{
+			StatusCode: 200,
+		}
+	}
+
+	log.Printf("Replying to chat %d in the webhook response", sendMessage.ChatId)
+
+	return &Response{
+		StatusCode: 200,
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       string(responseBody),
+	}
+}
+
+// markSeen tells whether the update is seen for the first time. Updates are
+// processed anyway when the store fails, since losing them is worse than
+// answering twice.
+func (b *Bot) markSeen(updateId int) bool {
+
+	if b.SeenUpdates == nil {
+		return true
+	}
+
+	firstTime, err := b.SeenUpdates.MarkSeen(updateId)
+
+	if err != nil {
+		log.Printf("Failed to check whether update %d was already handled: %v", updateId, err)
+
+		return true
+	}
+
+	return firstTime
+}
+
+// fallbackReply tells the chat, as precisely as possible, why its command couldn't be answered.
+func fallbackReply(ctx context.Context, err error) *commands.Reply {
+
+	if errors.Is(err, restclient.ErrCircuitOpen) {
+		return commands.TextReply(UnavailableReply)
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+		return commands.TextReply(TryAgainReply)
+	}
+
+	return commands.TextReply(FailureReply)
+}
+
+func (b *Bot) recordDeadLetter(letter *DeadLetter) {
+
+	if b.DeadLetters == nil {
+		return
+	}
+
+	letter.Time = time.Now()
+
+	if err := b.DeadLetters.Record(letter); err != nil {
+		log.Printf("Failed to record dead letter for update %d: %v", letter.UpdateId, err)
+	}
+}
+
+func newDeadLetter(request *commands.Request, body string, stage string, err error) *DeadLetter {
+
+	letter := &DeadLetter{
+		UpdateId: request.Update.UpdateId,
+		ChatId:   request.ChatId,
+		Stage:    stage,
+		Error:    err.Error(),
+		Body:     body,
+	}
+
+	if request.Invocation != nil {
+		letter.Command = request.Invocation.Name
+	}
+
+	return letter
+}
+
+func (b *Bot) clock() restclient.Clock {
+
+	if b.Clock == nil {
+		return restclient.SystemClock
+	}
+
+	return b.Clock
+}
+
+// commandContext derives the context commands run with, keeping the reply budget out of its deadline.
+func (b *Bot) commandContext(ctx context.Context) (context.Context, context.CancelFunc) {
+
+	deadline, ok := ctx.Deadline()
+
+	if !ok || b.Config.ReplyBudget <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithDeadline(ctx, deadline.Add(-b.Config.ReplyBudget))
+}
+
+func parseTelegramRequest(requestBody string) (*commands.Request, error) {
+	var update dto.Update
+
+	if err := json.Unmarshal([]byte(requestBody), &update); err != nil {
+
+		return nil, err
+	}
+
+	request := &commands.Request{
+		Update: &update,
+		User:   update.EffectiveUser(),
+	}
+
+	if chat := update.EffectiveChat(); chat != nil {
+		request.ChatId = chat.Id
+	}
+
+	return request, nil
+}
+
+// newSendMessageRequest addresses a reply to the chat of the request. Outside of
+// private chats the reply is threaded to the message holding the command.
+func newSendMessageRequest(request *commands.Request, message *dto.Message, reply *commands.Reply) *dto.SendMessageRequest {
+
+	sendMessage := &dto.SendMessageRequest{
+		ChatId:                request.ChatId,
+		Text:                  reply.Text,
+		ParseMode:             reply.ParseMode,
+		DisableWebPagePreview: reply.DisableWebPagePreview,
+		ReplyMarkup:           reply.ReplyMarkup,
+	}
+
+	if message.Chat.Type != dto.ChatTypePrivate {
+		sendMessage.ReplyToMessageId = message.MessageId
+	}
+
+	return sendMessage
+}
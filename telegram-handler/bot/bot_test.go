@@ -0,0 +1,1393 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"my-first-telegram-bot/telegram-handler/dto"
+	"my-first-telegram-bot/telegram-handler/restclient"
+	"my-first-telegram-bot/telegram-handler/utils/mocks"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf16"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingDeadLetterSink struct {
+	mu      sync.Mutex
+	letters []*DeadLetter
+}
+
+func (sink *recordingDeadLetterSink) Record(letter *DeadLetter) error {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+
+	sink.letters = append(sink.letters, letter)
+
+	return nil
+}
+
+// commandEntities marks the command the text starts with, like Telegram does.
+func commandEntities(text string) []dto.MessageEntity {
+
+	if !strings.HasPrefix(text, "/") {
+		return nil
+	}
+
+	command := strings.Fields(text)[0]
+
+	return []dto.MessageEntity{{Type: dto.EntityTypeBotCommand, Length: len(utf16.Encode([]rune(command)))}}
+}
+
+func TestHandlerFailedPostTelegramRequest(t *testing.T) {
+
+	t.Run("Failed Post Telegram Request", func(t *testing.T) {
+
+		expectedTelegramError := &restclient.TelegramAPIError{
+			Code:        500,
+			Description: "Internal Server Error",
+		}
+
+		myMockClient := &mocks.MockBaseClient{}
+
+		myMockClient.GetJokeFunc = func() (*dto.GeneratedJoke, error) {
+
+			return &dto.GeneratedJoke{
+				Type: "1",
+				Value: dto.JokeValue{
+					ID:         "1",
+					Joke:       "",
+					Categories: []string{"1", "2"},
+				},
+			}, nil
+		}
+
+		myMockClient.SendMessageFunc = func(message *dto.SendMessageRequest) (*dto.Message, error) {
+			return nil, expectedTelegramError
+		}
+
+		telegramRequest := dto.Update{
+			Message: dto.Message{
+				Text:     "/joke",
+				Entities: commandEntities("/joke"),
+				Chat: dto.Chat{
+					Id: 1234,
+				},
+			},
+			UpdateId: 1,
+		}
+
+		requestBody, err := json.Marshal(telegramRequest)
+
+		if err != nil {
+			t.Fatal("Can't run test scenario")
+		}
+
+		tempRequest := events.APIGatewayProxyRequest{
+			Body:       string(requestBody),
+			Path:       "http://myTelegramWebHookHandler.com/secretToken",
+			HTTPMethod: "POST",
+		}
+
+		deadLetters := &recordingDeadLetterSink{}
+
+		myBot := NewBot(myMockClient, myMockClient, myMockClient, Config{})
+
+		myBot.DeadLetters = deadLetters
+
+		// Act
+		response, err := myBot.Handler(tempRequest)
+
+		// Assert
+
+		assert.Equal(t, 1, myMockClient.ReturnGetJokeCallCount)
+
+		assert.Equal(t, 0, myMockClient.ReturnGetFactCallCount)
+
+		assert.Equal(t, 1, myMockClient.ReturnSendMessageCallCount)
+
+		assert.Nil(t, err)
+
+		assert.EqualValues(t, 200, response.StatusCode)
+
+		assert.EqualValues(t,
+			"Telegram API error 500: Internal Server Error",
+			response.Body)
+
+		assert.Equal(t, 1, len(deadLetters.letters))
+
+		assert.Equal(t, DeadLetterStageReply, deadLetters.letters[0].Stage)
+
+		assert.Equal(t, "joke", deadLetters.letters[0].Command)
+
+		assert.Equal(t, string(requestBody), deadLetters.letters[0].Body)
+	})
+}
+
+func TestHandlerFailedJokeRequest(t *testing.T) {
+
+	t.Run("Failed Joke Request", func(t *testing.T) {
+
+		myMockClient := &mocks.MockBaseClient{}
+
+		myMockClient.GetJokeFunc = func() (*dto.GeneratedJoke, error) {
+
+			return nil, restclient.ErrNon200Response
+		}
+
+		var sentMessage *dto.SendMessageRequest
+
+		myMockClient.SendMessageFunc = func(message *dto.SendMessageRequest) (*dto.Message, error) {
+			sentMessage = message
+			return &dto.Message{MessageId: 1, Chat: dto.Chat{Id: message.ChatId}}, nil
+		}
+
+		telegramRequest := dto.Update{
+			Message: dto.Message{
+				Text:     "/joke",
+				Entities: commandEntities("/joke"),
+				Chat: dto.Chat{
+					Id: 1234,
+				},
+			},
+			UpdateId: 1,
+		}
+
+		requestBody, err := json.Marshal(telegramRequest)
+
+		if err != nil {
+			t.Fatal("Can't run test scenario")
+		}
+
+		tempRequest := events.APIGatewayProxyRequest{
+			Body:       string(requestBody),
+			Path:       "http://myTelegramWebHookHandler.com/secretToken",
+			HTTPMethod: "POST",
+		}
+
+		deadLetters := &recordingDeadLetterSink{}
+
+		myBot := NewBot(myMockClient, myMockClient, myMockClient, Config{})
+
+		myBot.DeadLetters = deadLetters
+
+		// Act
+		response, err := myBot.Handler(tempRequest)
+
+		// Assert
+
+		assert.Equal(t, 1, myMockClient.ReturnGetJokeCallCount)
+
+		assert.Equal(t, 0, myMockClient.ReturnGetFactCallCount)
+
+		assert.Equal(t, 1, myMockClient.ReturnSendMessageCallCount)
+
+		assert.Nil(t, err)
+
+		assert.EqualValues(t, 200, response.StatusCode)
+
+		assert.EqualValues(t,
+			ErrorHttpRequest,
+			response.Body)
+
+		assert.Equal(t, FailureReply, sentMessage.Text)
+
+		assert.Equal(t, 1, len(deadLetters.letters))
+
+		assert.Equal(t, DeadLetterStageCommand, deadLetters.letters[0].Stage)
+
+		assert.Equal(t, restclient.ErrNon200Response.Error(), deadLetters.letters[0].Error)
+	})
+}
+
+func TestHandlerSuccessfulJokeRequest(t *testing.T) {
+
+	t.Run("Successful Joke Request", func(t *testing.T) {
+
+		myMockClient := &mocks.MockBaseClient{}
+
+		myMockClient.GetJokeFunc = func() (*dto.GeneratedJoke, error) {
+
+			return &dto.GeneratedJoke{
+				Type: "1",
+				Value: dto.JokeValue{
+					ID:         "1",
+					Joke:       "",
+					Categories: []string{"1", "2"},
+				},
+			}, nil
+		}
+
+		myMockClient.SendMessageFunc = func(message *dto.SendMessageRequest) (*dto.Message, error) {
+
+			return &dto.Message{
+				MessageId: 26,
+				From: &dto.User{
+					Id:        1025326803,
+					IsBot:     true,
+					FirstName: "MyDailyFact",
+					Username:  "majoFFper_bot",
+				},
+				Chat: dto.Chat{
+					Id:    -255361673,
+					Title: "Pokémons",
+					Type:  "group",
+				},
+				Date: 1614894279,
+				Text: "To Ensure Promptness, one is expected to pay beyond the value of service – hence the later abbreviation: T.I.P.",
+			}, nil
+		}
+
+		telegramRequest := dto.Update{
+			Message: dto.Message{
+				Text:     "/joke",
+				Entities: commandEntities("/joke"),
+				Chat: dto.Chat{
+					Id: 1234,
+				},
+			},
+			UpdateId: 1,
+		}
+
+		requestBody, err := json.Marshal(telegramRequest)
+
+		if err != nil {
+			t.Fatal("Can't run test scenario")
+		}
+
+		tempRequest := events.APIGatewayProxyRequest{
+			Body:       string(requestBody),
+			Path:       "http://myTelegramWebHookHandler.com/secretToken",
+			HTTPMethod: "POST",
+		}
+
+		myBot := NewBot(myMockClient, myMockClient, myMockClient, Config{})
+
+		// Act
+		response, err := myBot.Handler(tempRequest)
+
+		// Assert
+
+		assert.Equal(t, 1, myMockClient.ReturnGetJokeCallCount)
+
+		assert.Equal(t, 0, myMockClient.ReturnGetFactCallCount)
+
+		assert.Equal(t, 1, myMockClient.ReturnSendMessageCallCount)
+
+		assert.JSONEq(t,
+			`{"message_id": 26,"from": {"id": 1025326803,"is_bot": true,"first_name": "MyDailyFact","username": "majoFFper_bot"},"chat": {"id": -255361673,"title": "Pokémons","type": "group"},"date": 1614894279,"text": "To Ensure Promptness, one is expected to pay beyond the value of service – hence the later abbreviation: T.I.P."}`,
+			response.Body)
+	})
+}
+
+func TestHandlerFailedFactRequest(t *testing.T) {
+	t.Run("Failed Fact Request", func(t *testing.T) {
+
+		myMockClient := &mocks.MockBaseClient{}
+
+		myMockClient.GetFactFunc = func() (*dto.GeneratedFact, error) {
+
+			return nil, restclient.ErrNon200Response
+		}
+
+		var sentMessage *dto.SendMessageRequest
+
+		myMockClient.SendMessageFunc = func(message *dto.SendMessageRequest) (*dto.Message, error) {
+			sentMessage = message
+			return &dto.Message{MessageId: 1, Chat: dto.Chat{Id: message.ChatId}}, nil
+		}
+
+		telegramRequest := dto.Update{
+			Message: dto.Message{
+				Text:     "/fact",
+				Entities: commandEntities("/fact"),
+				Chat: dto.Chat{
+					Id: 1234,
+				},
+			},
+			UpdateId: 1,
+		}
+
+		requestBody, err := json.Marshal(telegramRequest)
+
+		if err != nil {
+			t.Fatal("Can't run test scenario")
+		}
+
+		tempRequest := events.APIGatewayProxyRequest{
+			Body:       string(requestBody),
+			Path:       "http://myTelegramWebHookHandler.com/secretToken",
+			HTTPMethod: "POST",
+		}
+
+		deadLetters := &recordingDeadLetterSink{}
+
+		myBot := NewBot(myMockClient, myMockClient, myMockClient, Config{})
+
+		myBot.DeadLetters = deadLetters
+
+		// Act
+		response, err := myBot.Handler(tempRequest)
+
+		// Assert
+
+		assert.Equal(t, 0, myMockClient.ReturnGetJokeCallCount)
+
+		assert.Equal(t, 1, myMockClient.ReturnGetFactCallCount)
+
+		assert.Equal(t, 1, myMockClient.ReturnSendMessageCallCount)
+
+		assert.Nil(t, err)
+
+		assert.EqualValues(t, 200, response.StatusCode)
+
+		assert.EqualValues(t,
+			ErrorHttpRequest,
+			response.Body)
+
+		assert.Equal(t, FailureReply, sentMessage.Text)
+
+		assert.Equal(t, 1, len(deadLetters.letters))
+
+		assert.Equal(t, DeadLetterStageCommand, deadLetters.letters[0].Stage)
+
+		assert.Equal(t, restclient.ErrNon200Response.Error(), deadLetters.letters[0].Error)
+	})
+}
+
+func TestHandlerSuccessfulFactRequest(t *testing.T) {
+
+	t.Run("Successful Fact Request", func(t *testing.T) {
+
+		myMockClient := &mocks.MockBaseClient{}
+
+		myMockClient.GetFactFunc = func() (*dto.GeneratedFact, error) {
+
+			return &dto.GeneratedFact{
+				ID:        "1",
+				Text:      "potato potato",
+				Source:    "potato potato",
+				SourceURL: "teste123",
+				Language:  "en",
+				Permalink: "teste124",
+			}, nil
+		}
+
+		myMockClient.SendMessageFunc = func(message *dto.SendMessageRequest) (*dto.Message, error) {
+
+			return &dto.Message{
+				MessageId: 26,
+				From: &dto.User{
+					Id:        1025326803,
+					IsBot:     true,
+					FirstName: "MyDailyFact",
+					Username:  "majoFFper_bot",
+				},
+				Chat: dto.Chat{
+					Id:    -255361673,
+					Title: "Pokémons",
+					Type:  "group",
+				},
+				Date: 1614894279,
+				Text: "To Ensure Promptness, one is expected to pay beyond the value of service – hence the later abbreviation: T.I.P.",
+			}, nil
+		}
+
+		telegramRequest := dto.Update{
+			Message: dto.Message{
+				Text:     "/fact",
+				Entities: commandEntities("/fact"),
+				Chat: dto.Chat{
+					Id: 1234,
+				},
+			},
+			UpdateId: 1,
+		}
+
+		requestBody, err := json.Marshal(telegramRequest)
+
+		tempRequest := events.APIGatewayProxyRequest{
+			Body:       string(requestBody),
+			Path:       "http://myTelegramWebHookHandler.com/secretToken",
+			HTTPMethod: "POST",
+		}
+
+		myBot := NewBot(myMockClient, myMockClient, myMockClient, Config{})
+
+		// Act
+		response, err := myBot.Handler(tempRequest)
+
+		if err != nil {
+			t.Fatal("Can't run test scenario")
+		}
+
+		// Assert
+
+		assert.Equal(t, 1, myMockClient.ReturnSendMessageCallCount)
+
+		assert.Equal(t, 1, myMockClient.ReturnGetFactCallCount)
+
+		assert.Equal(t, 0, myMockClient.ReturnGetJokeCallCount)
+
+		assert.JSONEq(t,
+			`{"message_id": 26,"from": {"id": 1025326803,"is_bot": true,"first_name": "MyDailyFact","username": "majoFFper_bot"},"chat": {"id": -255361673,"title": "Pokémons","type": "group"},"date": 1614894279,"text": "To Ensure Promptness, one is expected to pay beyond the value of service – hence the later abbreviation: T.I.P."}`,
+			response.Body)
+	})
+}
+
+func TestHandlerUnknownCommandRequest(t *testing.T) {
+
+	t.Run("Unknown Command Request", func(t *testing.T) {
+
+		var sentText string
+
+		myMockClient := &mocks.MockBaseClient{}
+
+		myMockClient.SendMessageFunc = func(message *dto.SendMessageRequest) (*dto.Message, error) {
+			sentText = message.Text
+			return &dto.Message{MessageId: 1, Chat: dto.Chat{Id: message.ChatId}}, nil
+		}
+
+		telegramRequest := dto.Update{
+			Message: dto.Message{
+				Text:     "/jokefact",
+				Entities: commandEntities("/jokefact"),
+				Chat: dto.Chat{
+					Id: 1234,
+				},
+			},
+			UpdateId: 1,
+		}
+
+		requestBody, err := json.Marshal(telegramRequest)
+
+		if err != nil {
+			t.Fatal("Can't run test scenario")
+		}
+
+		tempRequest := events.APIGatewayProxyRequest{
+			Body:       string(requestBody),
+			Path:       "http://myTelegramWebHookHandler.com/secretToken",
+			HTTPMethod: "POST",
+		}
+
+		myBot := NewBot(myMockClient, myMockClient, myMockClient, Config{})
+
+		// Act
+		response, err := myBot.Handler(tempRequest)
+
+		// Assert
+
+		assert.Nil(t, err)
+
+		assert.Equal(t, 0, myMockClient.ReturnGetJokeCallCount)
+
+		assert.Equal(t, 0, myMockClient.ReturnGetFactCallCount)
+
+		assert.Equal(t, 1, myMockClient.ReturnSendMessageCallCount)
+
+		assert.Contains(t, sentText, "/jokefact")
+
+		assert.Contains(t, sentText, "/fact - ")
+
+		assert.Contains(t, sentText, "/joke - ")
+
+		assert.EqualValues(t, 200, response.StatusCode)
+	})
+}
+
+func TestHandlerUnknownCommandInGroups(t *testing.T) {
+
+	scenarios := []struct {
+		name          string
+		text          string
+		chatType      string
+		expectedReply bool
+		expectedBody  string
+	}{
+		{name: "Unknown command in a private chat", text: "/weather", chatType: dto.ChatTypePrivate, expectedReply: true},
+		{name: "Unknown command in a group", text: "/weather", chatType: dto.ChatTypeGroup, expectedBody: UnaddressedCommand},
+		{name: "Unknown command in a supergroup", text: "/weather", chatType: dto.ChatTypeSupergroup, expectedBody: UnaddressedCommand},
+		{name: "Unknown command addressed to the bot in a group", text: "/weather@MyDailyFactBot", chatType: dto.ChatTypeGroup, expectedReply: true},
+		{name: "Unknown command addressed to another bot in a group", text: "/weather@WeatherBot", chatType: dto.ChatTypeGroup, expectedBody: InvalidInputFromTelegram},
+	}
+
+	for _, scenario := range scenarios {
+
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+
+			// Arrange
+			myMockClient := &mocks.MockBaseClient{}
+
+			myMockClient.SendMessageFunc = func(message *dto.SendMessageRequest) (*dto.Message, error) {
+				return &dto.Message{MessageId: 1, Chat: dto.Chat{Id: message.ChatId}}, nil
+			}
+
+			myBot := NewBot(myMockClient, myMockClient, myMockClient, Config{BotUsername: "MyDailyFactBot"})
+
+			// Act
+			response := myBot.ProcessUpdate(context.Background(), updateBody(t, dto.Update{
+				UpdateId: 1,
+				Message: dto.Message{
+					Text:     scenario.text,
+					Entities: commandEntities(scenario.text),
+					Chat:     dto.Chat{Id: -1001, Type: scenario.chatType},
+				},
+			}))
+
+			// Assert
+
+			if scenario.expectedReply {
+				assert.Equal(t, 1, myMockClient.ReturnSendMessageCallCount)
+			} else {
+				assert.Equal(t, 0, myMockClient.ReturnSendMessageCallCount)
+
+				assert.Equal(t, scenario.expectedBody, response.Body)
+			}
+		})
+	}
+}
+
+func TestHandlerCommandForAnotherBotWithUnknownUsername(t *testing.T) {
+
+	t.Run("Command addressed to another bot in a group", func(t *testing.T) {
+
+		// Arrange
+		myMockClient := &mocks.MockBaseClient{}
+
+		myMockClient.GetFactFunc = func() (*dto.GeneratedFact, error) {
+			return &dto.GeneratedFact{Text: "Fact"}, nil
+		}
+
+		myMockClient.SendMessageFunc = func(message *dto.SendMessageRequest) (*dto.Message, error) {
+			return &dto.Message{MessageId: 1, Chat: dto.Chat{Id: message.ChatId}}, nil
+		}
+
+		myBot := NewBot(myMockClient, myMockClient, myMockClient, Config{})
+
+		// Act
+		response := myBot.ProcessUpdate(context.Background(), updateBody(t, dto.Update{
+			UpdateId: 1,
+			Message: dto.Message{
+				Text:     "/fact@OtherBot",
+				Entities: commandEntities("/fact@OtherBot"),
+				Chat:     dto.Chat{Id: -1001, Type: dto.ChatTypeGroup},
+			},
+		}))
+
+		// Assert
+
+		assert.Equal(t, InvalidInputFromTelegram, response.Body)
+
+		assert.Equal(t, 0, myMockClient.ReturnGetFactCallCount)
+
+		assert.Equal(t, 0, myMockClient.ReturnSendMessageCallCount)
+	})
+}
+
+func TestHandlerIgnoredTextRequest(t *testing.T) {
+
+	scenarios := map[string]string{
+		"Command mid sentence":   "tell me a /fact please",
+		"Command for other bots": "/fact@SomeOtherBot",
+	}
+
+	for name, text := range scenarios {
+
+		t.Run(name, func(t *testing.T) {
+
+			telegramRequest := dto.Update{
+				Message: dto.Message{
+					Text:     text,
+					Entities: commandEntities(text),
+					Chat: dto.Chat{
+						Id: 1234,
+					},
+				},
+				UpdateId: 1,
+			}
+
+			requestBody, err := json.Marshal(telegramRequest)
+
+			if err != nil {
+				t.Fatal("Can't run test scenario")
+			}
+
+			tempRequest := events.APIGatewayProxyRequest{
+				Body:       string(requestBody),
+				Path:       "http://myTelegramWebHookHandler.com/secretToken",
+				HTTPMethod: "POST",
+			}
+
+			myMockClient := &mocks.MockBaseClient{}
+
+			myBot := NewBot(myMockClient, myMockClient, myMockClient, Config{BotUsername: "MyDailyFactBot"})
+
+			// Act
+			response, err := myBot.Handler(tempRequest)
+
+			// Assert
+
+			assert.Nil(t, err)
+
+			assert.Equal(t, 0, myMockClient.ReturnGetFactCallCount)
+
+			assert.Equal(t, 0, myMockClient.ReturnSendMessageCallCount)
+
+			assert.EqualValues(t,
+				InvalidInputFromTelegram,
+				response.Body)
+		})
+	}
+}
+
+func TestHandlerConcurrentRequests(t *testing.T) {
+
+	t.Run("Concurrent Requests reply to their own chat", func(t *testing.T) {
+
+		const parallelRequests = 50
+
+		var (
+			mu        sync.Mutex
+			sentTexts = map[int]string{}
+		)
+
+		myMockClient := &mocks.MockBaseClient{}
+
+		myMockClient.GetFactFunc = func() (*dto.GeneratedFact, error) {
+			return &dto.GeneratedFact{Text: "potato potato"}, nil
+		}
+
+		myMockClient.SendMessageFunc = func(message *dto.SendMessageRequest) (*dto.Message, error) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			sentTexts[message.ChatId] = message.Text
+
+			return &dto.Message{MessageId: 1, Chat: dto.Chat{Id: message.ChatId}}, nil
+		}
+
+		myBot := NewBot(myMockClient, myMockClient, myMockClient, Config{})
+
+		var wg sync.WaitGroup
+
+		// Act
+		for chatId := 1; chatId <= parallelRequests; chatId++ {
+
+			text := "/fact"
+
+			if chatId%2 == 0 {
+				text = fmt.Sprintf("/unknown_%d", chatId)
+			}
+
+			telegramRequest := dto.Update{
+				Message: dto.Message{
+					Text:     text,
+					Entities: commandEntities(text),
+					Chat: dto.Chat{
+						Id: chatId,
+					},
+				},
+				UpdateId: chatId,
+			}
+
+			requestBody, err := json.Marshal(telegramRequest)
+
+			if err != nil {
+				t.Fatal("Can't run test scenario")
+			}
+
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				_, err := myBot.Handler(events.APIGatewayProxyRequest{
+					Body:       string(requestBody),
+					HTTPMethod: "POST",
+				})
+
+				assert.Nil(t, err)
+			}()
+		}
+
+		wg.Wait()
+
+		// Assert
+
+		assert.Equal(t, parallelRequests/2, myMockClient.ReturnGetFactCallCount)
+
+		assert.Equal(t, parallelRequests, myMockClient.ReturnSendMessageCallCount)
+
+		for chatId := 1; chatId <= parallelRequests; chatId++ {
+
+			if chatId%2 == 0 {
+				assert.Contains(t, sentTexts[chatId], fmt.Sprintf("/unknown_%d", chatId))
+			} else {
+				assert.Equal(t, "potato potato", sentTexts[chatId])
+			}
+		}
+	})
+}
+
+func TestMultipleBotInstances(t *testing.T) {
+
+	t.Run("Bots only use their own clients", func(t *testing.T) {
+
+		var firstSentTexts, secondSentTexts []string
+
+		// Arrange
+		firstMockClient := &mocks.MockBaseClient{}
+
+		firstMockClient.GetFactFunc = func() (*dto.GeneratedFact, error) {
+			return &dto.GeneratedFact{Text: "potato potato"}, nil
+		}
+
+		firstMockClient.SendMessageFunc = func(message *dto.SendMessageRequest) (*dto.Message, error) {
+			firstSentTexts = append(firstSentTexts, message.Text)
+			return &dto.Message{MessageId: 1, Chat: dto.Chat{Id: message.ChatId}}, nil
+		}
+
+		secondMockClient := &mocks.MockBaseClient{}
+
+		secondMockClient.GetFactFunc = func() (*dto.GeneratedFact, error) {
+			return &dto.GeneratedFact{Text: "tomato tomato"}, nil
+		}
+
+		secondMockClient.SendMessageFunc = func(message *dto.SendMessageRequest) (*dto.Message, error) {
+			secondSentTexts = append(secondSentTexts, message.Text)
+			return &dto.Message{MessageId: 2, Chat: dto.Chat{Id: message.ChatId}}, nil
+		}
+
+		telegramRequest := dto.Update{
+			Message: dto.Message{
+				Text:     "/fact",
+				Entities: commandEntities("/fact"),
+				Chat: dto.Chat{
+					Id: 1234,
+				},
+			},
+			UpdateId: 1,
+		}
+
+		requestBody, err := json.Marshal(telegramRequest)
+
+		if err != nil {
+			t.Fatal("Can't run test scenario")
+		}
+
+		firstBot := NewBot(firstMockClient, firstMockClient, firstMockClient, Config{TelegramApiToken: "first"})
+		secondBot := NewBot(secondMockClient, secondMockClient, secondMockClient, Config{TelegramApiToken: "second"})
+
+		// Act
+		firstResponse, firstErr := firstBot.Handler(events.APIGatewayProxyRequest{Body: string(requestBody)})
+
+		secondResponse, secondErr := secondBot.Handler(events.APIGatewayProxyRequest{Body: string(requestBody)})
+
+		// Assert
+
+		assert.Nil(t, firstErr)
+
+		assert.Nil(t, secondErr)
+
+		assert.Equal(t, []string{"potato potato"}, firstSentTexts)
+
+		assert.Equal(t, []string{"tomato tomato"}, secondSentTexts)
+
+		assert.Equal(t, 1, firstMockClient.ReturnGetFactCallCount)
+
+		assert.Equal(t, 1, secondMockClient.ReturnGetFactCallCount)
+
+		assert.Contains(t, firstResponse.Body, `"message_id":1`)
+
+		assert.Contains(t, secondResponse.Body, `"message_id":2`)
+	})
+}
+
+func TestHandlerUpdateKinds(t *testing.T) {
+
+	scenarios := []struct {
+		name          string
+		update        dto.Update
+		expectedChats []int
+	}{
+		{
+			name: "Channel post",
+			update: dto.Update{
+				UpdateId: 1,
+				ChannelPost: &dto.Message{
+					Text:     "/fact",
+					Entities: commandEntities("/fact"),
+					Chat:     dto.Chat{Id: -1001, Type: "channel", Title: "Facts"},
+				},
+			},
+			expectedChats: []int{-1001},
+		},
+		{
+			name: "Photo caption",
+			update: dto.Update{
+				UpdateId: 2,
+				Message: dto.Message{
+					Caption:         "/fact",
+					CaptionEntities: commandEntities("/fact"),
+					Photo:           []dto.PhotoSize{{FileId: "AgAD"}},
+					Chat:            dto.Chat{Id: 1234},
+					From:            &dto.User{Id: 1, FirstName: "Mário"},
+				},
+			},
+			expectedChats: []int{1234},
+		},
+		{
+			name: "Edited message",
+			update: dto.Update{
+				UpdateId: 3,
+				EditedMessage: &dto.Message{
+					Text:     "/fact",
+					Entities: commandEntities("/fact"),
+					Chat:     dto.Chat{Id: 1234},
+				},
+			},
+		},
+	}
+
+	for _, scenario := range scenarios {
+
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+
+			var sentChats []int
+
+			myMockClient := &mocks.MockBaseClient{}
+
+			myMockClient.GetFactFunc = func() (*dto.GeneratedFact, error) {
+				return &dto.GeneratedFact{Text: "potato potato"}, nil
+			}
+
+			myMockClient.SendMessageFunc = func(message *dto.SendMessageRequest) (*dto.Message, error) {
+				sentChats = append(sentChats, message.ChatId)
+				return &dto.Message{MessageId: 1, Chat: dto.Chat{Id: message.ChatId}}, nil
+			}
+
+			requestBody, err := json.Marshal(scenario.update)
+
+			if err != nil {
+				t.Fatal("Can't run test scenario")
+			}
+
+			myBot := NewBot(myMockClient, myMockClient, myMockClient, Config{})
+
+			// Act
+			response, err := myBot.Handler(events.APIGatewayProxyRequest{Body: string(requestBody)})
+
+			// Assert
+
+			assert.Nil(t, err)
+
+			assert.EqualValues(t, 200, response.StatusCode)
+
+			assert.Equal(t, scenario.expectedChats, sentChats)
+		})
+	}
+}
+
+func TestHandlerFactReplyFormatting(t *testing.T) {
+
+	t.Run("Fact reply links its sources in thread", func(t *testing.T) {
+
+		var sentMessage *dto.SendMessageRequest
+
+		myMockClient := &mocks.MockBaseClient{}
+
+		myMockClient.GetFactFunc = func() (*dto.GeneratedFact, error) {
+
+			return &dto.GeneratedFact{
+				ID:        "1",
+				Text:      "Cats & dogs <3",
+				Source:    "djtech.net",
+				SourceURL: "http://www.djtech.net/humor/useless_facts.htm",
+				Language:  "en",
+				Permalink: "https://uselessfacts.jsph.pl/1",
+			}, nil
+		}
+
+		myMockClient.SendMessageFunc = func(message *dto.SendMessageRequest) (*dto.Message, error) {
+			sentMessage = message
+			return &dto.Message{MessageId: 1, Chat: dto.Chat{Id: message.ChatId}}, nil
+		}
+
+		telegramRequest := dto.Update{
+			Message: dto.Message{
+				MessageId: 26,
+				Text:      "/fact",
+				Entities:  commandEntities("/fact"),
+				Chat: dto.Chat{
+					Id:   -255361673,
+					Type: "group",
+				},
+			},
+			UpdateId: 1,
+		}
+
+		requestBody, err := json.Marshal(telegramRequest)
+
+		if err != nil {
+			t.Fatal("Can't run test scenario")
+		}
+
+		myBot := NewBot(myMockClient, myMockClient, myMockClient, Config{})
+
+		// Act
+		_, err = myBot.Handler(events.APIGatewayProxyRequest{Body: string(requestBody)})
+
+		// Assert
+
+		assert.Nil(t, err)
+
+		assert.EqualValues(t, &dto.SendMessageRequest{
+			ChatId:                -255361673,
+			Text:                  "Cats &amp; dogs &lt;3\n\n<a href=\"http://www.djtech.net/humor/useless_facts.htm\">djtech.net</a> · <a href=\"https://uselessfacts.jsph.pl/1\">Permalink</a>",
+			ParseMode:             dto.ParseModeHTML,
+			ReplyToMessageId:      26,
+			DisableWebPagePreview: true,
+		}, sentMessage)
+	})
+}
+
+func TestHandlerUnreachableChat(t *testing.T) {
+
+	scenarios := map[string]error{
+		"Chat not found": &restclient.TelegramAPIError{Code: 400, Description: "Bad Request: chat not found"},
+		"Bot blocked":    &restclient.TelegramAPIError{Code: 403, Description: "Forbidden: bot was blocked by the user"},
+	}
+
+	for name, telegramError := range scenarios {
+
+		telegramError := telegramError
+
+		t.Run(name, func(t *testing.T) {
+
+			myMockClient := &mocks.MockBaseClient{}
+
+			myMockClient.GetFactFunc = func() (*dto.GeneratedFact, error) {
+				return &dto.GeneratedFact{Text: "potato potato"}, nil
+			}
+
+			myMockClient.SendMessageFunc = func(message *dto.SendMessageRequest) (*dto.Message, error) {
+				return nil, telegramError
+			}
+
+			telegramRequest := dto.Update{
+				Message: dto.Message{
+					Text:     "/fact",
+					Entities: commandEntities("/fact"),
+					Chat: dto.Chat{
+						Id: 1234,
+					},
+				},
+				UpdateId: 1,
+			}
+
+			requestBody, err := json.Marshal(telegramRequest)
+
+			if err != nil {
+				t.Fatal("Can't run test scenario")
+			}
+
+			myBot := NewBot(myMockClient, myMockClient, myMockClient, Config{})
+
+			// Act
+			response, err := myBot.Handler(events.APIGatewayProxyRequest{Body: string(requestBody)})
+
+			// Assert
+
+			assert.Nil(t, err)
+
+			assert.EqualValues(t, 200, response.StatusCode)
+
+			assert.Equal(t, 1, myMockClient.ReturnSendMessageCallCount)
+		})
+	}
+}
+
+func TestHandlerCommandDeadline(t *testing.T) {
+
+	t.Run("Slow command leaves time to apologize", func(t *testing.T) {
+
+		var sentMessage *dto.SendMessageRequest
+
+		myMockClient := &mocks.MockBaseClient{}
+
+		myMockClient.SendMessageFunc = func(message *dto.SendMessageRequest) (*dto.Message, error) {
+			sentMessage = message
+			return &dto.Message{MessageId: 1, Chat: dto.Chat{Id: message.ChatId}}, nil
+		}
+
+		slowHttpClient := &mocks.MockHttpClient{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				<-req.Context().Done()
+				return nil, req.Context().Err()
+			},
+		}
+
+		factClient := restclient.NewBaseClient(slowHttpClient, "temp").
+			WithRetryPolicy(restclient.DefaultRetryPolicy)
+
+		telegramRequest := dto.Update{
+			Message: dto.Message{
+				Text:     "/fact",
+				Entities: commandEntities("/fact"),
+				Chat: dto.Chat{
+					Id: 1234,
+				},
+			},
+			UpdateId: 1,
+		}
+
+		requestBody, err := json.Marshal(telegramRequest)
+
+		if err != nil {
+			t.Fatal("Can't run test scenario")
+		}
+
+		myBot := NewBot(factClient, myMockClient, myMockClient, Config{ReplyBudget: 200 * time.Millisecond})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+		defer cancel()
+
+		// Act
+		response, err := myBot.HandlerWithContext(ctx, events.APIGatewayProxyRequest{Body: string(requestBody)})
+
+		// Assert
+
+		assert.Nil(t, err)
+
+		assert.Nil(t, ctx.Err())
+
+		assert.EqualValues(t, 200, response.StatusCode)
+
+		assert.Equal(t, 1, myMockClient.ReturnSendMessageCallCount)
+
+		assert.Equal(t, TryAgainReply, sentMessage.Text)
+	})
+}
+
+func TestHandlerOpenCircuit(t *testing.T) {
+
+	t.Run("Open circuit gets a friendly reply", func(t *testing.T) {
+
+		var sentMessage *dto.SendMessageRequest
+
+		myMockClient := &mocks.MockBaseClient{}
+
+		myMockClient.GetJokeFunc = func() (*dto.GeneratedJoke, error) {
+			return nil, restclient.ErrCircuitOpen
+		}
+
+		myMockClient.SendMessageFunc = func(message *dto.SendMessageRequest) (*dto.Message, error) {
+			sentMessage = message
+			return &dto.Message{MessageId: 1, Chat: dto.Chat{Id: message.ChatId}}, nil
+		}
+
+		telegramRequest := dto.Update{
+			Message: dto.Message{
+				Text:     "/joke",
+				Entities: commandEntities("/joke"),
+				Chat: dto.Chat{
+					Id: 1234,
+				},
+			},
+			UpdateId: 1,
+		}
+
+		requestBody, err := json.Marshal(telegramRequest)
+
+		if err != nil {
+			t.Fatal("Can't run test scenario")
+		}
+
+		myBot := NewBot(myMockClient, myMockClient, myMockClient, Config{})
+
+		// Act
+		response, err := myBot.Handler(events.APIGatewayProxyRequest{Body: string(requestBody)})
+
+		// Assert
+
+		assert.Nil(t, err)
+
+		assert.EqualValues(t, 200, response.StatusCode)
+
+		assert.Equal(t, 1, myMockClient.ReturnGetJokeCallCount)
+
+		assert.Equal(t, UnavailableReply, sentMessage.Text)
+	})
+}
+
+func TestHandlerPanicRecovery(t *testing.T) {
+
+	t.Run("Panicking command is acknowledged", func(t *testing.T) {
+
+		myMockClient := &mocks.MockBaseClient{}
+
+		myMockClient.GetFactFunc = func() (*dto.GeneratedFact, error) {
+			panic("batata")
+		}
+
+		telegramRequest := dto.Update{
+			Message: dto.Message{
+				Text:     "/fact",
+				Entities: commandEntities("/fact"),
+				Chat: dto.Chat{
+					Id: 1234,
+				},
+			},
+			UpdateId: 1,
+		}
+
+		requestBody, err := json.Marshal(telegramRequest)
+
+		if err != nil {
+			t.Fatal("Can't run test scenario")
+		}
+
+		deadLetters := &recordingDeadLetterSink{}
+
+		myBot := NewBot(myMockClient, myMockClient, myMockClient, Config{})
+
+		myBot.DeadLetters = deadLetters
+
+		// Act
+		response, err := myBot.Handler(events.APIGatewayProxyRequest{Body: string(requestBody)})
+
+		// Assert
+
+		assert.Nil(t, err)
+
+		assert.EqualValues(t, 200, response.StatusCode)
+
+		assert.Equal(t, 1, len(deadLetters.letters))
+
+		assert.Equal(t, DeadLetterStagePanic, deadLetters.letters[0].Stage)
+
+		assert.Equal(t, "batata", deadLetters.letters[0].Error)
+	})
+}
+
+type failingSeenUpdatesStore struct{}
+
+func (failingSeenUpdatesStore) MarkSeen(updateId int) (bool, error) {
+	return false, fmt.Errorf("disk full")
+}
+
+func TestHandlerRedeliveredUpdate(t *testing.T) {
+
+	t.Run("Redelivered update is handled once", func(t *testing.T) {
+
+		// Arrange
+		myMockClient := &mocks.MockBaseClient{}
+
+		myMockClient.GetFactFunc = func() (*dto.GeneratedFact, error) {
+			return &dto.GeneratedFact{Text: "fact"}, nil
+		}
+
+		myMockClient.SendMessageFunc = func(message *dto.SendMessageRequest) (*dto.Message, error) {
+			return &dto.Message{MessageId: 1, Chat: dto.Chat{Id: message.ChatId}}, nil
+		}
+
+		requestBody, err := json.Marshal(dto.Update{
+			Message: dto.Message{
+				Text:     "/fact",
+				Entities: commandEntities("/fact"),
+				Chat: dto.Chat{
+					Id: 1234,
+				},
+			},
+			UpdateId: 42,
+		})
+
+		if err != nil {
+			t.Fatal("Can't run test scenario")
+		}
+
+		myBot := NewBot(myMockClient, myMockClient, myMockClient, Config{})
+
+		// Act
+		myBot.Handler(events.APIGatewayProxyRequest{Body: string(requestBody)})
+
+		response, err := myBot.Handler(events.APIGatewayProxyRequest{Body: string(requestBody)})
+
+		// Assert
+
+		assert.Nil(t, err)
+
+		assert.EqualValues(t, 200, response.StatusCode)
+
+		assert.Equal(t, DuplicateUpdate, response.Body)
+
+		assert.Equal(t, 1, myMockClient.ReturnGetFactCallCount)
+
+		assert.Equal(t, 1, myMockClient.ReturnSendMessageCallCount)
+	})
+
+	t.Run("Failing store doesn't drop updates", func(t *testing.T) {
+
+		// Arrange
+		myMockClient := &mocks.MockBaseClient{}
+
+		myMockClient.GetFactFunc = func() (*dto.GeneratedFact, error) {
+			return &dto.GeneratedFact{Text: "fact"}, nil
+		}
+
+		myMockClient.SendMessageFunc = func(message *dto.SendMessageRequest) (*dto.Message, error) {
+			return &dto.Message{MessageId: 1, Chat: dto.Chat{Id: message.ChatId}}, nil
+		}
+
+		requestBody, err := json.Marshal(dto.Update{
+			Message: dto.Message{
+				Text:     "/fact",
+				Entities: commandEntities("/fact"),
+				Chat: dto.Chat{
+					Id: 1234,
+				},
+			},
+			UpdateId: 42,
+		})
+
+		if err != nil {
+			t.Fatal("Can't run test scenario")
+		}
+
+		myBot := NewBot(myMockClient, myMockClient, myMockClient, Config{})
+
+		myBot.SeenUpdates = failingSeenUpdatesStore{}
+
+		// Act
+		myBot.Handler(events.APIGatewayProxyRequest{Body: string(requestBody)})
+
+		myBot.Handler(events.APIGatewayProxyRequest{Body: string(requestBody)})
+
+		// Assert
+
+		assert.Equal(t, 2, myMockClient.ReturnGetFactCallCount)
+	})
+}
+
+func TestHandlerWebhookReply(t *testing.T) {
+
+	t.Run("Help is answered in the webhook response", func(t *testing.T) {
+
+		// Arrange
+		requestBody, err := json.Marshal(dto.Update{
+			Message: dto.Message{
+				MessageId: 7,
+				Text:      "/help",
+				Entities:  commandEntities("/help"),
+				Chat: dto.Chat{
+					Id:   1234,
+					Type: "group",
+				},
+			},
+			UpdateId: 1,
+		})
+
+		if err != nil {
+			t.Fatal("Can't run test scenario")
+		}
+
+		myMockClient := &mocks.MockBaseClient{}
+
+		myBot := NewBot(myMockClient, myMockClient, myMockClient, Config{})
+
+		// Act
+		response, err := myBot.Handler(events.APIGatewayProxyRequest{Body: string(requestBody)})
+
+		// Assert
+
+		assert.Nil(t, err)
+
+		assert.EqualValues(t, 200, response.StatusCode)
+
+		assert.Equal(t, 0, myMockClient.ReturnSendMessageCallCount)
+
+		assert.Equal(t, "application/json", response.Headers["Content-Type"])
+
+		expectedBody, _ := json.Marshal(map[string]interface{}{
+			"method":              "sendMessage",
+			"chat_id":             1234,
+			"text":                "Here is what I can do:\n" + myBot.commandList(),
+			"reply_to_message_id": 7,
+		})
+
+		assert.JSONEq(t, string(expectedBody), response.Body)
+	})
+
+	t.Run("Processed updates are always replied to through the client", func(t *testing.T) {
+
+		// Arrange
+		myMockClient := &mocks.MockBaseClient{}
+
+		myMockClient.SendMessageFunc = func(message *dto.SendMessageRequest) (*dto.Message, error) {
+			return &dto.Message{MessageId: 1, Chat: dto.Chat{Id: message.ChatId}}, nil
+		}
+
+		requestBody, err := json.Marshal(dto.Update{
+			Message: dto.Message{
+				Text:     "/help",
+				Entities: commandEntities("/help"),
+				Chat: dto.Chat{
+					Id:   1234,
+					Type: "private",
+				},
+			},
+			UpdateId: 1,
+		})
+
+		if err != nil {
+			t.Fatal("Can't run test scenario")
+		}
+
+		myBot := NewBot(myMockClient, myMockClient, myMockClient, Config{})
+
+		// Act
+		response := myBot.ProcessUpdate(context.Background(), string(requestBody))
+
+		// Assert
+
+		assert.EqualValues(t, 200, response.StatusCode)
+
+		assert.Equal(t, 1, myMockClient.ReturnSendMessageCallCount)
+	})
+}
+
+func TestJokeSourceFromConfig(t *testing.T) {
+
+	t.Run("The bot doesn't start with an unknown joke source", func(t *testing.T) {
+
+		// Act
+		myBot, err := NewBotFromConfig(Config{JokeSource: "jokesapi"})
+
+		// Assert
+		assert.Nil(t, myBot)
+
+		assert.True(t, errors.Is(err, restclient.ErrUnknownJokeSource))
+	})
+
+	t.Run("Jokes come from the default source unless configured otherwise", func(t *testing.T) {
+
+		// Act
+		myBot, err := NewBotFromConfig(Config{})
+
+		// Assert
+		assert.Nil(t, err)
+
+		assert.Equal(t, restclient.JokeSources[restclient.DefaultJokeSource].Categories, myBot.JokeClient.Categories())
+	})
+}
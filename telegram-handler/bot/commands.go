@@ -0,0 +1,129 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"my-first-telegram-bot/telegram-handler/commands"
+	"my-first-telegram-bot/telegram-handler/providers"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNoCommandsClient = errors.New("no client to publish the command menus with")
+
+	// CommandsSyncTimeout bounds publishing the command menus when the bot starts.
+	CommandsSyncTimeout = 5 * time.Second
+)
+
+func (b *Bot) registerCommands() {
+
+	fact := commands.ProviderCommand(providers.NewFactProvider(b.FactClient), "Get a random useless fact")
+
+	fact.Descriptions = map[string]string{
+		"de": "Eine zufällige nutzlose Tatsache",
+	}
+
+	b.registry.MustRegister(fact)
+
+	joke := commands.ProviderCommand(providers.NewJokeProvider(b.JokeClient), "Get a random joke")
+
+	joke.Descriptions = map[string]string{
+		"de": "Ein zufälliger Witz",
+	}
+
+	b.registry.MustRegister(joke)
+
+	b.registry.MustRegister(&commands.Command{
+		Name:        "settings",
+		Description: "Change the language, joke categories and explicit filter of this chat",
+		Descriptions: map[string]string{
+			"de": "Sprache, Witzkategorien und Filter für diesen Chat ändern",
+		},
+		Handler: b.handleSettings,
+	})
+
+	b.registry.MustRegister(&commands.Command{
+		Name:        "help",
+		Aliases:     []string{"start"},
+		Description: "List the available commands",
+		Descriptions: map[string]string{
+			"de": "Die verfügbaren Befehle auflisten",
+		},
+		Handler:      b.handleHelp,
+		WebhookReply: true,
+	})
+}
+
+// RegisterProvider serves the content of the provider with the command named
+// after it, so new sources can be plugged in without touching the dispatcher.
+func (b *Bot) RegisterProvider(provider commands.ContentProvider, description string) error {
+
+	return b.registry.Register(commands.ProviderCommand(provider, description))
+}
+
+// Menus are the command menus derived from the commands of the bot.
+func (b *Bot) Menus() []*commands.Menu {
+
+	return b.registry.Menus()
+}
+
+// SyncCommands publishes the command menus, so Telegram suggests the commands of the bot to its users.
+func (b *Bot) SyncCommands(ctx context.Context) error {
+
+	if b.CommandsClient == nil {
+		return ErrNoCommandsClient
+	}
+
+	for _, menu := range b.Menus() {
+
+		if err := b.CommandsClient.SetMyCommandsWithContext(ctx, menu.SetMyCommandsRequest()); err != nil {
+			return fmt.Errorf("publishing the %s menu for language %q: %w", menu.Scope, menu.LanguageCode, err)
+		}
+	}
+
+	return nil
+}
+
+// SyncCommandsOnStart publishes the command menus when the configuration asks to.
+// Failures are only logged, since the bot works without its menus.
+func (b *Bot) SyncCommandsOnStart(ctx context.Context) {
+
+	if !b.Config.SyncCommandsOnStart {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, CommandsSyncTimeout)
+	defer cancel()
+
+	if err := b.SyncCommands(ctx); err != nil {
+		log.Printf("Failed to publish the command menus: %v", err)
+
+		return
+	}
+
+	log.Printf("Published the command menus")
+}
+
+func (b *Bot) handleHelp(ctx context.Context, request *commands.Request) (*commands.Reply, error) {
+
+	return commands.TextReply("Here is what I can do:\n" + b.commandList()), nil
+}
+
+func (b *Bot) unknownCommandReply(name string) *commands.Reply {
+
+	return commands.TextReply(fmt.Sprintf("Sorry, I don't know the /%s command. Here is what I can do:\n%s", name, b.commandList()))
+}
+
+func (b *Bot) commandList() string {
+
+	var builder strings.Builder
+
+	for _, command := range b.registry.Commands() {
+		fmt.Fprintf(&builder, "/%s - %s\n", command.Name, command.Description)
+	}
+
+	return strings.TrimSuffix(builder.String(), "\n")
+}
@@ -0,0 +1,228 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"my-first-telegram-bot/telegram-handler/commands"
+	"my-first-telegram-bot/telegram-handler/dto"
+	"my-first-telegram-bot/telegram-handler/utils/mocks"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingCommandsClient struct {
+	requests []*dto.SetMyCommandsRequest
+	err      error
+}
+
+func (client *recordingCommandsClient) SetMyCommandsWithContext(ctx context.Context, request *dto.SetMyCommandsRequest) error {
+
+	client.requests = append(client.requests, request)
+
+	return client.err
+}
+
+func TestSyncCommands(t *testing.T) {
+
+	t.Run("Every menu is published", func(t *testing.T) {
+
+		// Arrange
+		commandsClient := &recordingCommandsClient{}
+
+		myMockClient := &mocks.MockBaseClient{}
+
+		myBot := NewBot(myMockClient, myMockClient, myMockClient, Config{})
+
+		myBot.CommandsClient = commandsClient
+
+		// Act
+		err := myBot.SyncCommands(context.Background())
+
+		// Assert
+
+		assert.Nil(t, err)
+
+		assert.Equal(t, 2, len(commandsClient.requests))
+
+		assert.Equal(t, "", commandsClient.requests[0].LanguageCode)
+
+		assert.Equal(t, "de", commandsClient.requests[1].LanguageCode)
+
+		assert.Equal(t, []dto.BotCommand{
+			{Command: "fact", Description: "Get a random useless fact"},
+			{Command: "help", Description: "List the available commands"},
+			{Command: "joke", Description: "Get a random joke"},
+			{Command: "settings", Description: "Change the language, joke categories and explicit filter of this chat"},
+		}, commandsClient.requests[0].Commands)
+	})
+
+	t.Run("Failed sync on start is tolerated", func(t *testing.T) {
+
+		// Arrange
+		commandsClient := &recordingCommandsClient{err: errors.New("batata")}
+
+		myMockClient := &mocks.MockBaseClient{}
+
+		myBot := NewBot(myMockClient, myMockClient, myMockClient, Config{SyncCommandsOnStart: true})
+
+		myBot.CommandsClient = commandsClient
+
+		// Act
+		myBot.SyncCommandsOnStart(context.Background())
+
+		// Assert
+
+		assert.Equal(t, 1, len(commandsClient.requests))
+	})
+
+	t.Run("No sync on start unless configured", func(t *testing.T) {
+
+		// Arrange
+		commandsClient := &recordingCommandsClient{}
+
+		myMockClient := &mocks.MockBaseClient{}
+
+		myBot := NewBot(myMockClient, myMockClient, myMockClient, Config{})
+
+		myBot.CommandsClient = commandsClient
+
+		// Act
+		myBot.SyncCommandsOnStart(context.Background())
+
+		// Assert
+
+		assert.Equal(t, 0, len(commandsClient.requests))
+	})
+
+	t.Run("Missing client", func(t *testing.T) {
+
+		// Arrange
+		myMockClient := &mocks.MockBaseClient{}
+
+		myBot := NewBot(myMockClient, myMockClient, myMockClient, Config{})
+
+		// Act
+		err := myBot.SyncCommands(context.Background())
+
+		// Assert
+
+		assert.Equal(t, ErrNoCommandsClient, err)
+	})
+}
+
+type quoteProvider struct {
+	args []string
+}
+
+func (provider *quoteProvider) Name() string {
+	return "quote"
+}
+
+func (provider *quoteProvider) Fetch(ctx context.Context, args string) (*commands.Reply, error) {
+
+	provider.args = append(provider.args, args)
+
+	return commands.TextReply("Simplicity is prerequisite for reliability."), nil
+}
+
+func TestRegisterProvider(t *testing.T) {
+
+	t.Run("Plugged in providers are dispatched to", func(t *testing.T) {
+
+		var sentText string
+
+		// Arrange
+		myMockClient := &mocks.MockBaseClient{}
+
+		myMockClient.SendMessageFunc = func(message *dto.SendMessageRequest) (*dto.Message, error) {
+
+			sentText = message.Text
+
+			return &dto.Message{MessageId: 1, Chat: dto.Chat{Id: message.ChatId}}, nil
+		}
+
+		requestBody, err := json.Marshal(dto.Update{
+			Message: dto.Message{
+				Text:     "/quote dijkstra",
+				Entities: commandEntities("/quote dijkstra"),
+				Chat: dto.Chat{
+					Id: 1234,
+				},
+			},
+			UpdateId: 1,
+		})
+
+		if err != nil {
+			t.Fatal("Can't run test scenario")
+		}
+
+		provider := &quoteProvider{}
+
+		myBot := NewBot(myMockClient, myMockClient, myMockClient, Config{})
+
+		// Act
+		registerErr := myBot.RegisterProvider(provider, "Get a quote")
+
+		duplicateErr := myBot.RegisterProvider(provider, "Get another quote")
+
+		myBot.ProcessUpdate(context.Background(), string(requestBody))
+
+		// Assert
+
+		assert.Nil(t, registerErr)
+
+		assert.Equal(t, commands.ErrDuplicateCommand, duplicateErr)
+
+		assert.Equal(t, []string{"dijkstra"}, provider.args)
+
+		assert.Equal(t, "Simplicity is prerequisite for reliability.", sentText)
+
+		assert.Contains(t, myBot.commandList(), "/quote - Get a quote")
+	})
+}
+
+func TestProviderArguments(t *testing.T) {
+
+	t.Run("Bad arguments are answered with the usage of the command", func(t *testing.T) {
+
+		var sentText string
+
+		// Arrange
+		myMockClient := &mocks.MockBaseClient{}
+
+		myMockClient.SendMessageFunc = func(message *dto.SendMessageRequest) (*dto.Message, error) {
+
+			sentText = message.Text
+
+			return &dto.Message{MessageId: 1, Chat: dto.Chat{Id: message.ChatId}}, nil
+		}
+
+		requestBody, err := json.Marshal(dto.Update{
+			Message: dto.Message{
+				Text:     "/fact pt",
+				Entities: commandEntities("/fact pt"),
+				Chat: dto.Chat{
+					Id: 1234,
+				},
+			},
+			UpdateId: 1,
+		})
+
+		if err != nil {
+			t.Fatal("Can't run test scenario")
+		}
+
+		myBot := NewBot(myMockClient, myMockClient, myMockClient, Config{})
+
+		// Act
+		myBot.ProcessUpdate(context.Background(), string(requestBody))
+
+		// Assert
+
+		assert.Equal(t, 0, myMockClient.ReturnGetFactCallCount)
+
+		assert.Equal(t, "Usage: /fact [today|random] [en|de]", sentText)
+	})
+}
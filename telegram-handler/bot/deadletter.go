@@ -0,0 +1,45 @@
+package bot
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+)
+
+const (
+	DeadLetterStageCommand = "command"
+	DeadLetterStageReply   = "reply"
+	DeadLetterStagePanic   = "panic"
+)
+
+// DeadLetter describes an update the bot acknowledged but couldn't fully handle.
+type DeadLetter struct {
+	UpdateId int       `json:"update_id"`
+	ChatId   int       `json:"chat_id"`
+	Command  string    `json:"command,omitempty"`
+	Stage    string    `json:"stage"`
+	Error    string    `json:"error"`
+	Body     string    `json:"body"`
+	Time     time.Time `json:"time"`
+}
+
+// DeadLetterSink keeps the updates that failed so they can be looked at, or replayed, later.
+type DeadLetterSink interface {
+	Record(letter *DeadLetter) error
+}
+
+// LogDeadLetterSink writes dead letters as JSON lines to the log, which ends up in CloudWatch on Lambda.
+type LogDeadLetterSink struct{}
+
+func (LogDeadLetterSink) Record(letter *DeadLetter) error {
+
+	encoded, err := json.Marshal(letter)
+
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Dead letter: %s", encoded)
+
+	return nil
+}
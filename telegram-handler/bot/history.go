@@ -0,0 +1,93 @@
+package bot
+
+import (
+	"context"
+	"log"
+	"my-first-telegram-bot/telegram-handler/commands"
+	"time"
+)
+
+var (
+	// DefaultHistorySize is how many delivered items are remembered per chat.
+	DefaultHistorySize = 100
+
+	// DefaultHistoryTTL is how long a delivered item is remembered.
+	DefaultHistoryTTL = 7 * 24 * time.Hour
+
+	// MaxRefetches caps how many times a command runs again because its reply repeats
+	// content the chat already got. The last reply is sent even if it repeats.
+	MaxRefetches = 3
+)
+
+// runCommand runs the command, running it again while its reply repeats content the
+// chat already got, up to MaxRefetches times. When running it again fails, the
+// repeated reply is sent rather than none.
+func (b *Bot) runCommand(ctx context.Context, command *commands.Command, request *commands.Request) (*commands.Reply, error) {
+
+	reply, err := command.Handler(ctx, request)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for refetches := 0; refetches < MaxRefetches && b.alreadySent(ctx, request.ChatId, historyKey(command, reply)); refetches++ {
+
+		log.Printf("Chat %d already got %s, running /%s again", request.ChatId, historyKey(command, reply), command.Name)
+
+		refetched, err := command.Handler(commands.NewRefetchContext(ctx), request)
+
+		if err != nil {
+			log.Printf("Failed to run /%s again, sending %s anyway: %v", command.Name, historyKey(command, reply), err)
+
+			break
+		}
+
+		reply = refetched
+	}
+
+	return reply, nil
+}
+
+// historyKey identifies the content of the reply among the content of every command,
+// or is empty when the reply can repeat.
+func historyKey(command *commands.Command, reply *commands.Reply) string {
+
+	if reply == nil || reply.ItemId == "" {
+		return ""
+	}
+
+	return command.Name + ":" + reply.ItemId
+}
+
+// alreadySent tells whether the chat already got the content. The content is sent
+// anyway when the history fails, since a repeated answer is better than none.
+func (b *Bot) alreadySent(ctx context.Context, chatId int, key string) bool {
+
+	if b.History == nil || key == "" {
+		return false
+	}
+
+	seen, err := b.History.Seen(ctx, chatId, key)
+
+	if err != nil {
+		log.Printf("Failed to check whether chat %d already got %s: %v", chatId, key, err)
+
+		return false
+	}
+
+	return seen
+}
+
+// rememberSent adds the content to the history of the chat once it is sent. It runs
+// against the context of the update rather than of the command, since replies can
+// be sent after the command deadline, within the reply budget.
+func (b *Bot) rememberSent(ctx context.Context, chatId int, key string) {
+
+	if b.History == nil || key == "" {
+		return
+	}
+
+	if err := b.History.Add(ctx, chatId, key); err != nil {
+		log.Printf("Failed to remember that chat %d got %s: %v", chatId, key, err)
+	}
+}
@@ -0,0 +1,309 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"my-first-telegram-bot/telegram-handler/dto"
+	"my-first-telegram-bot/telegram-handler/utils/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// failingHistory fails to look up or remember any item.
+type failingHistory struct{}
+
+func (failingHistory) Seen(ctx context.Context, chatId int, itemId string) (bool, error) {
+	return false, errors.New("history unavailable")
+}
+
+func (failingHistory) Add(ctx context.Context, chatId int, itemId string) error {
+	return errors.New("history unavailable")
+}
+
+// contextRecordingHistory records the error of the context of every item added.
+type contextRecordingHistory struct {
+	addErrors []error
+}
+
+func (h *contextRecordingHistory) Seen(ctx context.Context, chatId int, itemId string) (bool, error) {
+	return false, nil
+}
+
+func (h *contextRecordingHistory) Add(ctx context.Context, chatId int, itemId string) error {
+
+	h.addErrors = append(h.addErrors, ctx.Err())
+
+	return nil
+}
+
+func TestHistory(t *testing.T) {
+
+	scenarios := []struct {
+		name              string
+		command           string
+		factIds           []string
+		jokeIds           []string
+		chatIds           []int
+		expectedTexts     []string
+		expectedFactCalls int
+		expectedJokeCalls int
+	}{
+		{
+			name:              "Repeated facts are fetched again",
+			command:           "/fact",
+			factIds:           []string{"1", "1", "1", "2"},
+			chatIds:           []int{1234, 1234},
+			expectedTexts:     []string{"Fact 1", "Fact 2"},
+			expectedFactCalls: 4,
+		},
+		{
+			name:              "Facts repeat after the retry cap",
+			command:           "/fact",
+			factIds:           []string{"1", "1", "1", "1", "1", "2"},
+			chatIds:           []int{1234, 1234},
+			expectedTexts:     []string{"Fact 1", "Fact 1"},
+			expectedFactCalls: 5,
+		},
+		{
+			name:              "Other chats get the same fact",
+			command:           "/fact",
+			factIds:           []string{"1", "1"},
+			chatIds:           []int{1234, 5678},
+			expectedTexts:     []string{"Fact 1", "Fact 1"},
+			expectedFactCalls: 2,
+		},
+		{
+			name:              "Repeated jokes are fetched again",
+			command:           "/joke",
+			jokeIds:           []string{"7", "7", "8", "7", "9"},
+			chatIds:           []int{1234, 1234, 1234},
+			expectedTexts:     []string{"Joke 7", "Joke 8", "Joke 9"},
+			expectedJokeCalls: 5,
+		},
+		{
+			name:              "Jokes without ids can repeat",
+			command:           "/joke",
+			jokeIds:           []string{"", ""},
+			chatIds:           []int{1234, 1234},
+			expectedTexts:     []string{"Joke ", "Joke "},
+			expectedJokeCalls: 2,
+		},
+	}
+
+	for _, scenario := range scenarios {
+
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+
+			var sentTexts []string
+
+			factIds := scenario.factIds
+			jokeIds := scenario.jokeIds
+
+			// Arrange
+			myMockClient := &mocks.MockBaseClient{}
+
+			myMockClient.GetFactFunc = func() (*dto.GeneratedFact, error) {
+				id := factIds[0]
+				factIds = factIds[1:]
+				return &dto.GeneratedFact{ID: id, Text: "Fact " + id}, nil
+			}
+
+			myMockClient.GetJokeFunc = func() (*dto.GeneratedJoke, error) {
+				id := jokeIds[0]
+				jokeIds = jokeIds[1:]
+				return &dto.GeneratedJoke{Source: "jokeapi", Value: dto.JokeValue{ID: id, Joke: "Joke " + id}}, nil
+			}
+
+			myMockClient.SendMessageFunc = func(message *dto.SendMessageRequest) (*dto.Message, error) {
+				sentTexts = append(sentTexts, message.Text)
+				return &dto.Message{MessageId: 1, Chat: dto.Chat{Id: message.ChatId}}, nil
+			}
+
+			myBot := NewBot(myMockClient, myMockClient, myMockClient, Config{})
+
+			// Act
+			for i, chatId := range scenario.chatIds {
+				myBot.ProcessUpdate(context.Background(), updateBody(t, dto.Update{
+					UpdateId: i + 1,
+					Message:  dto.Message{Text: scenario.command, Entities: commandEntities(scenario.command), Chat: dto.Chat{Id: chatId}},
+				}))
+			}
+
+			// Assert
+
+			assert.Equal(t, scenario.expectedTexts, sentTexts)
+
+			assert.Equal(t, scenario.expectedFactCalls, myMockClient.ReturnGetFactCallCount)
+
+			assert.Equal(t, scenario.expectedJokeCalls, myMockClient.ReturnGetJokeCallCount)
+		})
+	}
+
+	t.Run("Refetched facts are random ones", func(t *testing.T) {
+
+		// Arrange
+		myMockClient := &mocks.MockBaseClient{}
+
+		myMockClient.GetFactFunc = func() (*dto.GeneratedFact, error) {
+			return &dto.GeneratedFact{ID: "1", Text: "Fact 1"}, nil
+		}
+
+		myMockClient.SendMessageFunc = func(message *dto.SendMessageRequest) (*dto.Message, error) {
+			return &dto.Message{MessageId: 1, Chat: dto.Chat{Id: message.ChatId}}, nil
+		}
+
+		myBot := NewBot(myMockClient, myMockClient, myMockClient, Config{})
+
+		// Act
+		for updateId := 1; updateId <= 2; updateId++ {
+			myBot.ProcessUpdate(context.Background(), updateBody(t, dto.Update{
+				UpdateId: updateId,
+				Message:  dto.Message{Text: "/fact", Entities: commandEntities("/fact"), Chat: dto.Chat{Id: 1234}},
+			}))
+		}
+
+		// Assert
+		assert.Equal(t, &dto.FactQuery{Kind: dto.FactKindRandom}, myMockClient.LastFactQuery)
+	})
+
+	t.Run("Repeated facts are sent when fetching again fails", func(t *testing.T) {
+
+		var (
+			factCalls int
+			sentTexts []string
+		)
+
+		// Arrange
+		myMockClient := &mocks.MockBaseClient{}
+
+		myMockClient.GetFactFunc = func() (*dto.GeneratedFact, error) {
+
+			factCalls++
+
+			if factCalls > 2 {
+				return nil, errors.New("facts unavailable")
+			}
+
+			return &dto.GeneratedFact{ID: "1", Text: "Fact 1"}, nil
+		}
+
+		myMockClient.SendMessageFunc = func(message *dto.SendMessageRequest) (*dto.Message, error) {
+			sentTexts = append(sentTexts, message.Text)
+			return &dto.Message{MessageId: 1, Chat: dto.Chat{Id: message.ChatId}}, nil
+		}
+
+		myBot := NewBot(myMockClient, myMockClient, myMockClient, Config{})
+
+		// Act
+		for updateId := 1; updateId <= 2; updateId++ {
+			myBot.ProcessUpdate(context.Background(), updateBody(t, dto.Update{
+				UpdateId: updateId,
+				Message:  dto.Message{Text: "/fact", Entities: commandEntities("/fact"), Chat: dto.Chat{Id: 1234}},
+			}))
+		}
+
+		// Assert
+
+		assert.Equal(t, []string{"Fact 1", "Fact 1"}, sentTexts)
+
+		assert.Equal(t, 3, myMockClient.ReturnGetFactCallCount)
+	})
+
+	t.Run("Items are remembered past the command deadline", func(t *testing.T) {
+
+		// Arrange
+		myMockClient := &mocks.MockBaseClient{}
+
+		myMockClient.GetFactFunc = func() (*dto.GeneratedFact, error) {
+			return &dto.GeneratedFact{ID: "1", Text: "Fact 1"}, nil
+		}
+
+		myMockClient.SendMessageFunc = func(message *dto.SendMessageRequest) (*dto.Message, error) {
+			return &dto.Message{MessageId: 1, Chat: dto.Chat{Id: message.ChatId}}, nil
+		}
+
+		history := &contextRecordingHistory{}
+
+		myBot := NewBot(myMockClient, myMockClient, myMockClient, Config{ReplyBudget: time.Hour})
+		myBot.History = history
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		// Act
+		myBot.ProcessUpdate(ctx, updateBody(t, dto.Update{
+			UpdateId: 1,
+			Message:  dto.Message{Text: "/fact", Entities: commandEntities("/fact"), Chat: dto.Chat{Id: 1234}},
+		}))
+
+		// Assert
+		assert.Equal(t, []error{nil}, history.addErrors)
+	})
+
+	t.Run("Items aren't remembered when the reply isn't sent", func(t *testing.T) {
+
+		var sendCalls int
+
+		// Arrange
+		myMockClient := &mocks.MockBaseClient{}
+
+		myMockClient.GetFactFunc = func() (*dto.GeneratedFact, error) {
+			return &dto.GeneratedFact{ID: "1", Text: "Fact 1"}, nil
+		}
+
+		myMockClient.SendMessageFunc = func(message *dto.SendMessageRequest) (*dto.Message, error) {
+			sendCalls++
+
+			if sendCalls == 1 {
+				return nil, errors.New("chat not found")
+			}
+
+			return &dto.Message{MessageId: 1, Chat: dto.Chat{Id: message.ChatId}}, nil
+		}
+
+		myBot := NewBot(myMockClient, myMockClient, myMockClient, Config{})
+
+		// Act
+		for updateId := 1; updateId <= 2; updateId++ {
+			myBot.ProcessUpdate(context.Background(), updateBody(t, dto.Update{
+				UpdateId: updateId,
+				Message:  dto.Message{Text: "/fact", Entities: commandEntities("/fact"), Chat: dto.Chat{Id: 1234}},
+			}))
+		}
+
+		// Assert
+		assert.Equal(t, 2, myMockClient.ReturnGetFactCallCount)
+	})
+
+	t.Run("Facts are sent when the history fails", func(t *testing.T) {
+
+		// Arrange
+		myMockClient := &mocks.MockBaseClient{}
+
+		myMockClient.GetFactFunc = func() (*dto.GeneratedFact, error) {
+			return &dto.GeneratedFact{ID: "1", Text: "Fact 1"}, nil
+		}
+
+		myMockClient.SendMessageFunc = func(message *dto.SendMessageRequest) (*dto.Message, error) {
+			return &dto.Message{MessageId: 1, Chat: dto.Chat{Id: message.ChatId}}, nil
+		}
+
+		myBot := NewBot(myMockClient, myMockClient, myMockClient, Config{})
+		myBot.History = failingHistory{}
+
+		// Act
+		myBot.ProcessUpdate(context.Background(), updateBody(t, dto.Update{
+			UpdateId: 1,
+			Message:  dto.Message{Text: "/fact", Entities: commandEntities("/fact"), Chat: dto.Chat{Id: 1234}},
+		}))
+
+		// Assert
+		assert.Equal(t, 1, myMockClient.ReturnGetFactCallCount)
+
+		assert.Equal(t, 1, myMockClient.ReturnSendMessageCallCount)
+	})
+}
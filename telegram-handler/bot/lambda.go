@@ -0,0 +1,158 @@
+package bot
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// The event formats the Lambda function can be invoked with.
+const (
+	// EventFormatAuto tells the format of each event from its version.
+	EventFormatAuto = "auto"
+	// EventFormatRestApi is the API Gateway REST API proxy format, also used by the HTTP API payload format 1.0.
+	EventFormatRestApi = "rest"
+	// EventFormatHttpApi is the API Gateway HTTP API payload format 2.0, also used by Lambda Function URLs.
+	EventFormatHttpApi = "http"
+)
+
+// LambdaHandler returns the Lambda handler taking events of the configured format.
+func (b *Bot) LambdaHandler() (interface{}, error) {
+
+	switch b.Config.EventFormat {
+	case EventFormatAuto, "":
+		return b.HandleEvent, nil
+	case EventFormatRestApi:
+		return b.HandlerWithContext, nil
+	case EventFormatHttpApi:
+		return b.HandlerV2WithContext, nil
+	}
+
+	return nil, fmt.Errorf("unknown event format %q", b.Config.EventFormat)
+}
+
+// Handler processes a Telegram webhook request without any deadline.
+func (b *Bot) Handler(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+
+	return b.HandlerWithContext(context.Background(), request)
+}
+
+// HandlerWithContext is the Lambda handler processing the Telegram webhook requests
+// coming through an API Gateway REST API. See HandleWebhook for how they are handled.
+func (b *Bot) HandlerWithContext(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+
+	body, err := eventBody(request.Body, request.IsBase64Encoded)
+
+	if err != nil {
+		return events.APIGatewayProxyResponse{
+			StatusCode: 400,
+		}, nil
+	}
+
+	response := b.HandleWebhook(ctx, &WebhookRequest{
+		Path:    request.Path,
+		Headers: proxyRequestHeaders(request),
+		Body:    body,
+	})
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: response.StatusCode,
+		Headers:    response.Headers,
+		Body:       response.Body,
+	}, nil
+}
+
+// HandlerV2WithContext is the Lambda handler processing the Telegram webhook requests
+// coming through an API Gateway HTTP API or a Lambda Function URL.
+func (b *Bot) HandlerV2WithContext(ctx context.Context, request events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+
+	body, err := eventBody(request.Body, request.IsBase64Encoded)
+
+	if err != nil {
+		return events.APIGatewayV2HTTPResponse{
+			StatusCode: 400,
+		}, nil
+	}
+
+	response := b.HandleWebhook(ctx, &WebhookRequest{
+		Path:    request.RawPath,
+		Headers: request.Headers,
+		Body:    body,
+	})
+
+	return events.APIGatewayV2HTTPResponse{
+		StatusCode: response.StatusCode,
+		Headers:    response.Headers,
+		Body:       response.Body,
+	}, nil
+}
+
+// HandleEvent is the Lambda handler taking the events of any format, telling
+// them apart by their version.
+func (b *Bot) HandleEvent(ctx context.Context, event json.RawMessage) (interface{}, error) {
+
+	var versioned struct {
+		Version string `json:"version"`
+	}
+
+	if err := json.Unmarshal(event, &versioned); err != nil {
+		return nil, err
+	}
+
+	if versioned.Version == "2.0" {
+
+		var request events.APIGatewayV2HTTPRequest
+
+		if err := json.Unmarshal(event, &request); err != nil {
+			return nil, err
+		}
+
+		return b.HandlerV2WithContext(ctx, request)
+	}
+
+	var request events.APIGatewayProxyRequest
+
+	if err := json.Unmarshal(event, &request); err != nil {
+		return nil, err
+	}
+
+	return b.HandlerWithContext(ctx, request)
+}
+
+// eventBody decodes the body of the event when API Gateway encoded it.
+func eventBody(body string, isBase64Encoded bool) (string, error) {
+
+	if !isBase64Encoded {
+		return body, nil
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(body)
+
+	if err != nil {
+		return "", err
+	}
+
+	return string(decoded), nil
+}
+
+// proxyRequestHeaders merges the single and multi value headers of the request,
+// keeping the first value of the latter.
+func proxyRequestHeaders(request events.APIGatewayProxyRequest) map[string]string {
+
+	headers := make(map[string]string, len(request.Headers)+len(request.MultiValueHeaders))
+
+	for name, values := range request.MultiValueHeaders {
+		if len(values) > 0 {
+			headers[name] = values[0]
+		}
+	}
+
+	for name, value := range request.Headers {
+		headers[name] = value
+	}
+
+	return headers
+}
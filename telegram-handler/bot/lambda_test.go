@@ -0,0 +1,164 @@
+package bot
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"my-first-telegram-bot/telegram-handler/dto"
+	"my-first-telegram-bot/telegram-handler/utils/mocks"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func helpUpdateBody(t *testing.T) string {
+
+	requestBody, err := json.Marshal(dto.Update{
+		Message: dto.Message{
+			Text:     "/help",
+			Entities: commandEntities("/help"),
+			Chat: dto.Chat{
+				Id:   1234,
+				Type: "private",
+			},
+		},
+		UpdateId: 1,
+	})
+
+	if err != nil {
+		t.Fatal("Can't run test scenario")
+	}
+
+	return string(requestBody)
+}
+
+func TestHandleEvent(t *testing.T) {
+
+	t.Run("Function URL event", func(t *testing.T) {
+
+		// Arrange
+		event, err := json.Marshal(map[string]interface{}{
+			"version":         "2.0",
+			"routeKey":        "$default",
+			"rawPath":         "/telegram/pathToken",
+			"headers":         map[string]string{"x-telegram-bot-api-secret-token": "s3cr3t", "content-type": "application/json"},
+			"body":            helpUpdateBody(t),
+			"isBase64Encoded": false,
+		})
+
+		if err != nil {
+			t.Fatal("Can't run test scenario")
+		}
+
+		myMockClient := &mocks.MockBaseClient{}
+
+		myBot := NewBot(myMockClient, myMockClient, myMockClient, Config{
+			WebhookSecretToken: "s3cr3t",
+			WebhookPathToken:   "pathToken",
+		})
+
+		// Act
+		response, err := myBot.HandleEvent(context.Background(), event)
+
+		// Assert
+
+		assert.Nil(t, err)
+
+		httpApiResponse, ok := response.(events.APIGatewayV2HTTPResponse)
+
+		assert.True(t, ok)
+
+		assert.Equal(t, 200, httpApiResponse.StatusCode)
+
+		assert.Contains(t, httpApiResponse.Body, "\"method\":\"sendMessage\"")
+	})
+
+	t.Run("REST API event with an encoded body", func(t *testing.T) {
+
+		// Arrange
+		event, err := json.Marshal(events.APIGatewayProxyRequest{
+			Path:            "/telegram",
+			HTTPMethod:      "POST",
+			Body:            base64.StdEncoding.EncodeToString([]byte(helpUpdateBody(t))),
+			IsBase64Encoded: true,
+		})
+
+		if err != nil {
+			t.Fatal("Can't run test scenario")
+		}
+
+		myMockClient := &mocks.MockBaseClient{}
+
+		myBot := NewBot(myMockClient, myMockClient, myMockClient, Config{})
+
+		// Act
+		response, err := myBot.HandleEvent(context.Background(), event)
+
+		// Assert
+
+		assert.Nil(t, err)
+
+		restApiResponse, ok := response.(events.APIGatewayProxyResponse)
+
+		assert.True(t, ok)
+
+		assert.Equal(t, 200, restApiResponse.StatusCode)
+
+		assert.Contains(t, restApiResponse.Body, "\"method\":\"sendMessage\"")
+	})
+
+	t.Run("Rejected HTTP API event", func(t *testing.T) {
+
+		// Arrange
+		myMockClient := &mocks.MockBaseClient{}
+
+		myBot := NewBot(myMockClient, myMockClient, myMockClient, Config{WebhookSecretToken: "s3cr3t"})
+
+		// Act
+		response, err := myBot.HandlerV2WithContext(context.Background(), events.APIGatewayV2HTTPRequest{
+			Version: "2.0",
+			RawPath: "/telegram",
+			Body:    helpUpdateBody(t),
+		})
+
+		// Assert
+
+		assert.Nil(t, err)
+
+		assert.Equal(t, 401, response.StatusCode)
+	})
+}
+
+func TestLambdaHandler(t *testing.T) {
+
+	scenarios := map[string]bool{
+		"":                 true,
+		EventFormatAuto:    true,
+		EventFormatRestApi: true,
+		EventFormatHttpApi: true,
+		"batata":           false,
+	}
+
+	for format, valid := range scenarios {
+
+		format, valid := format, valid
+
+		t.Run("Event format "+format, func(t *testing.T) {
+
+			// Arrange
+			myMockClient := &mocks.MockBaseClient{}
+
+			myBot := NewBot(myMockClient, myMockClient, myMockClient, Config{EventFormat: format})
+
+			// Act
+			handler, err := myBot.LambdaHandler()
+
+			// Assert
+
+			assert.Equal(t, valid, err == nil)
+
+			assert.Equal(t, valid, handler != nil)
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"log"
+	"my-first-telegram-bot/telegram-handler/commands"
+	"my-first-telegram-bot/telegram-handler/dto"
+	"my-first-telegram-bot/telegram-handler/settings"
+	"strings"
+	"time"
+)
+
+const (
+	// PunchlineDeliveryDelay sends the follow up of a reply on its own, once the punchline delay is over.
+	PunchlineDeliveryDelay = "delay"
+	// PunchlineDeliveryButton sends the follow up of a reply when the button under the reply is pressed.
+	PunchlineDeliveryButton = "button"
+
+	// The callback data of the buttons carries either the punchline itself or the command
+	// and item id to fetch it again with, so any instance of the bot can answer the press.
+	punchlineCallbackPrefix = "punchline:"
+	punchlineTextPrefix     = punchlineCallbackPrefix + "t:"
+	punchlineItemPrefix     = punchlineCallbackPrefix + "i:"
+)
+
+var (
+	ShowPunchlineButton = "Show punchline"
+	PunchlineGoneReply  = "That punchline is gone, ask for another /joke!"
+
+	// DefaultPunchlineDelay is how long the punchline of a joke waits after its setup.
+	DefaultPunchlineDelay = 2 * time.Second
+
+	errUnknownPunchline = errors.New("the button doesn't say which punchline to show")
+)
+
+// splitFollowUp separates the reply to send right away from the follow up to send
+// after the punchline delay. With button delivery the reply gets a button to show the
+// follow up instead, unless the follow up can't be rebuilt from the callback data of
+// the button.
+func (b *Bot) splitFollowUp(command *commands.Command, reply *commands.Reply) (*commands.Reply, *commands.Reply) {
+
+	followUp := reply.FollowUp
+
+	if followUp == nil {
+		return reply, nil
+	}
+
+	withoutFollowUp := *reply
+	withoutFollowUp.FollowUp = nil
+
+	if b.Config.PunchlineDelivery != PunchlineDeliveryButton {
+		return &withoutFollowUp, followUp
+	}
+
+	callbackData, ok := punchlineCallbackData(command, reply)
+
+	if !ok {
+		log.Printf("The punchline doesn't fit in a button, sending it after a delay instead")
+
+		return &withoutFollowUp, followUp
+	}
+
+	withoutFollowUp.ReplyMarkup = dto.InlineKeyboardMarkup{
+		InlineKeyboard: [][]dto.InlineKeyboardButton{{
+			{Text: ShowPunchlineButton, CallbackData: callbackData},
+		}},
+	}
+
+	return &withoutFollowUp, nil
+}
+
+// punchlineCallbackData fits the follow up of the reply in the callback data of a
+// button when it is short plain text. Otherwise it refers to the item of the reply,
+// when its command can fetch the follow up again.
+func punchlineCallbackData(command *commands.Command, reply *commands.Reply) (string, bool) {
+
+	followUp := reply.FollowUp
+
+	if followUp.ParseMode == "" && followUp.ReplyMarkup == nil && followUp.FollowUp == nil {
+
+		if data := punchlineTextPrefix + followUp.Text; len(data) <= dto.MaxCallbackDataLength {
+			return data, true
+		}
+	}
+
+	if command != nil && command.FollowUp != nil && reply.ItemId != "" {
+
+		if data := punchlineItemPrefix + command.Name + ":" + reply.ItemId; len(data) <= dto.MaxCallbackDataLength {
+			return data, true
+		}
+	}
+
+	return "", false
+}
+
+// sendFollowUp sends the follow up of a reply once the punchline delay is over. It
+// is sent early rather than dropped when the delay would eat into the reply budget.
+func (b *Bot) sendFollowUp(ctx context.Context, request *commands.Request, message *dto.Message, body string, followUp *commands.Reply) {
+
+	delayCtx, cancel := b.commandContext(ctx)
+
+	if err := b.clock().Sleep(delayCtx, b.punchlineDelay()); err != nil {
+		log.Printf("No time left to wait for the punchline in chat %d, sending it now", request.ChatId)
+	}
+
+	cancel()
+
+	sentMessage, err := b.TelegramClient.SendMessageWithContext(ctx, newSendMessageRequest(request, message, followUp))
+
+	if err != nil {
+		log.Printf("Failed to send the punchline to chat %d: %v", request.ChatId, err)
+
+		b.recordDeadLetter(newDeadLetter(request, body, DeadLetterStageReply, err))
+
+		return
+	}
+
+	log.Printf("Sent punchline %d to chat %d", sentMessage.MessageId, sentMessage.Chat.Id)
+}
+
+// handleCallbackQuery answers the presses of the buttons showing punchlines. The
+// punchline is sent to the chat in reply to the message holding the button.
+func (b *Bot) handleCallbackQuery(ctx context.Context, request *commands.Request, body string) *Response {
+
+	query := request.Update.CallbackQuery
+
+	if !strings.HasPrefix(query.Data, punchlineCallbackPrefix) || query.Message == nil {
+		log.Printf(InvalidInputFromTelegram)
+
+		return &Response{
+			StatusCode: 200,
+			Body:       InvalidInputFromTelegram,
+		}
+	}
+
+	punchline, err := b.rebuildPunchline(ctx, request, query.Data)
+
+	if err != nil {
+		log.Printf("Failed to rebuild the punchline for chat %d: %v", request.ChatId, err)
+
+		b.answerCallbackQuery(ctx, &dto.AnswerCallbackQueryRequest{
+			CallbackQueryId: query.Id,
+			Text:            PunchlineGoneReply,
+		})
+
+		return &Response{
+			StatusCode: 200,
+			Body:       PunchlineGoneReply,
+		}
+	}
+
+	b.answerCallbackQuery(ctx, &dto.AnswerCallbackQueryRequest{CallbackQueryId: query.Id})
+
+	sendMessage := newSendMessageRequest(request, query.Message, punchline)
+	sendMessage.ReplyToMessageId = query.Message.MessageId
+
+	sentMessage, err := b.TelegramClient.SendMessageWithContext(ctx, sendMessage)
+
+	if err != nil {
+		log.Printf("Failed to send the punchline to chat %d: %v", request.ChatId, err)
+
+		b.recordDeadLetter(newDeadLetter(request, body, DeadLetterStageReply, err))
+
+		return &Response{
+			StatusCode: 200,
+			Body:       err.Error(),
+		}
+	}
+
+	log.Printf("Sent punchline %d to chat %d", sentMessage.MessageId, sentMessage.Chat.Id)
+
+	return sentMessageResponse(sentMessage)
+}
+
+// rebuildPunchline gets the punchline the callback data of the button stands for,
+// fetching it again through its command when the button only refers to it.
+func (b *Bot) rebuildPunchline(ctx context.Context, request *commands.Request, data string) (*commands.Reply, error) {
+
+	if strings.HasPrefix(data, punchlineTextPrefix) {
+		return commands.TextReply(strings.TrimPrefix(data, punchlineTextPrefix)), nil
+	}
+
+	if !strings.HasPrefix(data, punchlineItemPrefix) {
+		return nil, errUnknownPunchline
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(data, punchlineItemPrefix), ":", 2)
+
+	if len(parts) != 2 {
+		return nil, errUnknownPunchline
+	}
+
+	command, found := b.registry.Lookup(parts[0])
+
+	if !found || command.FollowUp == nil {
+		return nil, errUnknownPunchline
+	}
+
+	commandCtx, cancel := b.commandContext(ctx)
+	defer cancel()
+
+	settingsCtx := settings.NewContext(commandCtx, b.chatSettings(commandCtx, request.ChatId))
+
+	return command.FollowUp(settingsCtx, parts[1])
+}
+
+// answerCallbackQuery stops the button from spinning. Failing to do so is only logged,
+// since the user gets the punchline anyway.
+func (b *Bot) answerCallbackQuery(ctx context.Context, request *dto.AnswerCallbackQueryRequest) {
+
+	if b.CallbackClient == nil {
+		return
+	}
+
+	if err := b.CallbackClient.AnswerCallbackQueryWithContext(ctx, request); err != nil {
+		log.Printf("Failed to answer callback query %s: %v", request.CallbackQueryId, err)
+	}
+}
+
+func (b *Bot) punchlineDelay() time.Duration {
+
+	if b.Config.PunchlineDelay > 0 {
+		return b.Config.PunchlineDelay
+	}
+
+	return DefaultPunchlineDelay
+}
@@ -0,0 +1,348 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"my-first-telegram-bot/telegram-handler/dto"
+	"my-first-telegram-bot/telegram-handler/utils/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingCallbackClient records the callback queries it is asked to answer.
+type recordingCallbackClient struct {
+	answers []*dto.AnswerCallbackQueryRequest
+}
+
+func (c *recordingCallbackClient) AnswerCallbackQueryWithContext(ctx context.Context, request *dto.AnswerCallbackQueryRequest) error {
+	c.answers = append(c.answers, request)
+	return nil
+}
+
+func twoPartJoke() (*dto.GeneratedJoke, error) {
+	return &dto.GeneratedJoke{
+		Type: dto.JokeTypeTwoPart,
+		Value: dto.JokeValue{
+			ID:       "232",
+			Setup:    "Why do programmers prefer dark mode?",
+			Delivery: "Because light attracts bugs.",
+		},
+	}, nil
+}
+
+func longTwoPartJoke() (*dto.GeneratedJoke, error) {
+	return &dto.GeneratedJoke{
+		Type:   dto.JokeTypeTwoPart,
+		Source: "jokeapi",
+		Value: dto.JokeValue{
+			ID:       "42",
+			Setup:    "Why did the developer go broke?",
+			Delivery: "Because it had too many bugs, and not even the exterminator could fix them all.",
+		},
+	}, nil
+}
+
+// buttonPress presses the button with the callback data under message 21.
+func buttonPress(updateId int, queryId string, callbackData string) dto.Update {
+	return dto.Update{
+		UpdateId: updateId,
+		CallbackQuery: &dto.CallbackQuery{
+			Id:      queryId,
+			From:    dto.User{Id: 1, FirstName: "Mário"},
+			Message: &dto.Message{MessageId: 21, Chat: dto.Chat{Id: 1234, Type: "private"}},
+			Data:    callbackData,
+		},
+	}
+}
+
+func updateBody(t *testing.T, update dto.Update) string {
+
+	body, err := json.Marshal(update)
+
+	if err != nil {
+		t.Fatal("Can't run test scenario")
+	}
+
+	return string(body)
+}
+
+func TestPunchlineDelivery(t *testing.T) {
+
+	jokeRequest := dto.Update{
+		UpdateId: 1,
+		Message: dto.Message{
+			MessageId: 10,
+			Text:      "/joke",
+			Entities:  commandEntities("/joke"),
+			Chat:      dto.Chat{Id: 1234, Type: "private"},
+		},
+	}
+
+	t.Run("Punchline follows the setup after the delay", func(t *testing.T) {
+
+		var sentMessages []*dto.SendMessageRequest
+
+		// Arrange
+		myMockClient := &mocks.MockBaseClient{}
+
+		myMockClient.GetJokeFunc = twoPartJoke
+
+		myMockClient.SendMessageFunc = func(message *dto.SendMessageRequest) (*dto.Message, error) {
+			sentMessages = append(sentMessages, message)
+			return &dto.Message{MessageId: 20 + len(sentMessages), Chat: dto.Chat{Id: message.ChatId}}, nil
+		}
+
+		clock := &mocks.MockClock{}
+
+		myBot := NewBot(myMockClient, myMockClient, myMockClient, Config{PunchlineDelay: 3 * time.Second})
+		myBot.Clock = clock
+
+		// Act
+		response := myBot.ProcessUpdate(context.Background(), updateBody(t, jokeRequest))
+
+		// Assert
+
+		assert.Equal(t, 200, response.StatusCode)
+
+		assert.Equal(t, 2, len(sentMessages))
+
+		assert.Equal(t, "Why do programmers prefer dark mode?", sentMessages[0].Text)
+
+		assert.Nil(t, sentMessages[0].ReplyMarkup)
+
+		assert.Equal(t, "Because light attracts bugs.", sentMessages[1].Text)
+
+		assert.Equal(t, []time.Duration{3 * time.Second}, clock.Slept)
+	})
+
+	t.Run("Punchline is sent right away when there is no time left to wait", func(t *testing.T) {
+
+		var sentMessages []*dto.SendMessageRequest
+
+		// Arrange
+		myMockClient := &mocks.MockBaseClient{}
+
+		myMockClient.GetJokeFunc = twoPartJoke
+
+		myMockClient.SendMessageFunc = func(message *dto.SendMessageRequest) (*dto.Message, error) {
+			sentMessages = append(sentMessages, message)
+			return &dto.Message{MessageId: 20 + len(sentMessages), Chat: dto.Chat{Id: message.ChatId}}, nil
+		}
+
+		myBot := NewBot(myMockClient, myMockClient, myMockClient, Config{ReplyBudget: time.Hour})
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		// Act
+		start := time.Now()
+
+		myBot.ProcessUpdate(ctx, updateBody(t, jokeRequest))
+
+		// Assert
+
+		assert.Equal(t, 2, len(sentMessages))
+
+		assert.True(t, time.Since(start) < DefaultPunchlineDelay)
+	})
+
+	t.Run("Punchline shows up when the button is pressed", func(t *testing.T) {
+
+		var sentMessages []*dto.SendMessageRequest
+
+		// Arrange
+		myMockClient := &mocks.MockBaseClient{}
+
+		myMockClient.GetJokeFunc = twoPartJoke
+
+		myMockClient.SendMessageFunc = func(message *dto.SendMessageRequest) (*dto.Message, error) {
+			sentMessages = append(sentMessages, message)
+			return &dto.Message{MessageId: 20 + len(sentMessages), Chat: dto.Chat{Id: message.ChatId}}, nil
+		}
+
+		callbackClient := &recordingCallbackClient{}
+
+		myBot := NewBot(myMockClient, myMockClient, myMockClient, Config{PunchlineDelivery: PunchlineDeliveryButton})
+		myBot.CallbackClient = callbackClient
+
+		myBot.ProcessUpdate(context.Background(), updateBody(t, jokeRequest))
+
+		if len(sentMessages) != 1 {
+			t.Fatal("Can't run test scenario")
+		}
+
+		keyboard, ok := sentMessages[0].ReplyMarkup.(dto.InlineKeyboardMarkup)
+
+		if !ok {
+			t.Fatal("Can't run test scenario")
+		}
+
+		// Act
+		response := myBot.ProcessUpdate(context.Background(), updateBody(t, buttonPress(2, "first", keyboard.InlineKeyboard[0][0].CallbackData)))
+
+		// Assert
+
+		assert.Equal(t, "Why do programmers prefer dark mode?", sentMessages[0].Text)
+
+		assert.Equal(t, ShowPunchlineButton, keyboard.InlineKeyboard[0][0].Text)
+
+		assert.Equal(t, "punchline:t:Because light attracts bugs.", keyboard.InlineKeyboard[0][0].CallbackData)
+
+		assert.Equal(t, 200, response.StatusCode)
+
+		assert.Equal(t, 2, len(sentMessages))
+
+		assert.Equal(t, "Because light attracts bugs.", sentMessages[1].Text)
+
+		assert.Equal(t, 21, sentMessages[1].ReplyToMessageId)
+
+		assert.Equal(t, []*dto.AnswerCallbackQueryRequest{{CallbackQueryId: "first"}}, callbackClient.answers)
+	})
+
+	t.Run("Long punchlines are fetched again by any instance when the button is pressed", func(t *testing.T) {
+
+		var sentMessages []*dto.SendMessageRequest
+
+		// Arrange
+		myMockClient := &mocks.MockBaseClient{}
+
+		myMockClient.GetJokeFunc = longTwoPartJoke
+
+		myMockClient.SendMessageFunc = func(message *dto.SendMessageRequest) (*dto.Message, error) {
+			sentMessages = append(sentMessages, message)
+			return &dto.Message{MessageId: 20 + len(sentMessages), Chat: dto.Chat{Id: message.ChatId}}, nil
+		}
+
+		config := Config{PunchlineDelivery: PunchlineDeliveryButton}
+
+		myBot := NewBot(myMockClient, myMockClient, myMockClient, config)
+
+		myBot.ProcessUpdate(context.Background(), updateBody(t, jokeRequest))
+
+		keyboard, ok := sentMessages[0].ReplyMarkup.(dto.InlineKeyboardMarkup)
+
+		if !ok {
+			t.Fatal("Can't run test scenario")
+		}
+
+		otherInstance := NewBot(myMockClient, myMockClient, myMockClient, config)
+
+		// Act
+		response := otherInstance.ProcessUpdate(context.Background(), updateBody(t, buttonPress(2, "first", keyboard.InlineKeyboard[0][0].CallbackData)))
+
+		// Assert
+
+		assert.Equal(t, "punchline:i:joke:jokeapi:42", keyboard.InlineKeyboard[0][0].CallbackData)
+
+		assert.Equal(t, 200, response.StatusCode)
+
+		assert.Equal(t, 2, len(sentMessages))
+
+		assert.Equal(t, "Because it had too many bugs, and not even the exterminator could fix them all.", sentMessages[1].Text)
+
+		assert.Equal(t, "42", myMockClient.LastJokeQuery.ID)
+	})
+
+	t.Run("Punchlines that can't be rebuilt are gone", func(t *testing.T) {
+
+		scenarios := map[string]string{
+			"Joke no longer available": "punchline:i:joke:jokeapi:42",
+			"Unknown command":          "punchline:i:riddle:42",
+			"Saved by an older bot":    "punchline:0123456789abcdef",
+		}
+
+		for name, callbackData := range scenarios {
+
+			// Arrange
+			myMockClient := &mocks.MockBaseClient{}
+
+			myMockClient.GetJokeFunc = func() (*dto.GeneratedJoke, error) {
+				return nil, errors.New("joke not found")
+			}
+
+			myMockClient.SendMessageFunc = func(message *dto.SendMessageRequest) (*dto.Message, error) {
+				t.Fatal("No message expected")
+				return nil, nil
+			}
+
+			callbackClient := &recordingCallbackClient{}
+
+			myBot := NewBot(myMockClient, myMockClient, myMockClient, Config{PunchlineDelivery: PunchlineDeliveryButton})
+			myBot.CallbackClient = callbackClient
+
+			// Act
+			response := myBot.ProcessUpdate(context.Background(), updateBody(t, buttonPress(2, "first", callbackData)))
+
+			// Assert
+
+			assert.Equal(t, PunchlineGoneReply, response.Body, name)
+
+			assert.Equal(t, []*dto.AnswerCallbackQueryRequest{{CallbackQueryId: "first", Text: PunchlineGoneReply}}, callbackClient.answers, name)
+		}
+	})
+
+	t.Run("Punchlines that don't fit in a button follow after the delay", func(t *testing.T) {
+
+		var sentMessages []*dto.SendMessageRequest
+
+		// Arrange
+		myMockClient := &mocks.MockBaseClient{}
+
+		myMockClient.GetJokeFunc = func() (*dto.GeneratedJoke, error) {
+			joke, err := longTwoPartJoke()
+			joke.Value.ID = ""
+			return joke, err
+		}
+
+		myMockClient.SendMessageFunc = func(message *dto.SendMessageRequest) (*dto.Message, error) {
+			sentMessages = append(sentMessages, message)
+			return &dto.Message{MessageId: 20 + len(sentMessages), Chat: dto.Chat{Id: message.ChatId}}, nil
+		}
+
+		myBot := NewBot(myMockClient, myMockClient, myMockClient, Config{PunchlineDelivery: PunchlineDeliveryButton})
+		myBot.Clock = &mocks.MockClock{}
+
+		// Act
+		myBot.ProcessUpdate(context.Background(), updateBody(t, jokeRequest))
+
+		// Assert
+
+		assert.Equal(t, 2, len(sentMessages))
+
+		assert.Nil(t, sentMessages[0].ReplyMarkup)
+
+		assert.Equal(t, "Because it had too many bugs, and not even the exterminator could fix them all.", sentMessages[1].Text)
+	})
+
+	t.Run("Buttons of other bots are ignored", func(t *testing.T) {
+
+		// Arrange
+		myMockClient := &mocks.MockBaseClient{}
+
+		myMockClient.SendMessageFunc = func(message *dto.SendMessageRequest) (*dto.Message, error) {
+			t.Fatal("No message expected")
+			return nil, nil
+		}
+
+		myBot := NewBot(myMockClient, myMockClient, myMockClient, Config{})
+
+		update := dto.Update{
+			UpdateId: 4,
+			CallbackQuery: &dto.CallbackQuery{
+				Id:      "other",
+				Message: &dto.Message{MessageId: 21, Chat: dto.Chat{Id: 1234}},
+				Data:    "vote:yes",
+			},
+		}
+
+		// Act
+		response := myBot.ProcessUpdate(context.Background(), updateBody(t, update))
+
+		// Assert
+
+		assert.Equal(t, InvalidInputFromTelegram, response.Body)
+	})
+}
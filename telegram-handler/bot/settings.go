@@ -0,0 +1,216 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"my-first-telegram-bot/telegram-handler/commands"
+	"my-first-telegram-bot/telegram-handler/restclient"
+	"my-first-telegram-bot/telegram-handler/settings"
+	"strings"
+)
+
+const anyJokeCategory = "any"
+
+var ErrNoChatSettingsStore = errors.New("no store to keep the chat settings in")
+
+// newChatSettingsStore keeps the chat settings in the configured DynamoDB table. Failing
+// to connect to it is an error rather than a reason to keep them elsewhere, since
+// settings saved anywhere else would be lost on the next cold start.
+func newChatSettingsStore(config Config) (settings.ChatSettingsStore, error) {
+
+	if config.ChatSettingsTable == "" {
+		return newLocalChatSettingsStore(config), nil
+	}
+
+	store, err := settings.NewDynamoDBStoreFromEnv(config.ChatSettingsTable, config.DynamoDBEndpoint)
+
+	if err != nil {
+		return nil, fmt.Errorf("connecting to the chat settings table %s: %w", config.ChatSettingsTable, err)
+	}
+
+	return store, nil
+}
+
+// newLocalChatSettingsStore keeps the chat settings in the configured file, or in
+// memory when there is none.
+func newLocalChatSettingsStore(config Config) settings.ChatSettingsStore {
+
+	if config.ChatSettingsFile != "" {
+		return settings.NewFileStore(config.ChatSettingsFile)
+	}
+
+	return settings.NewMemoryStore()
+}
+
+// chatSettings loads the settings of the chat. The defaults are used when the store
+// fails, since a command answered without them is better than no answer.
+func (b *Bot) chatSettings(ctx context.Context, chatId int) *settings.ChatSettings {
+
+	if b.ChatSettings == nil {
+		return &settings.ChatSettings{}
+	}
+
+	chatSettings, err := b.ChatSettings.Get(ctx, chatId)
+
+	if err != nil {
+		log.Printf("Failed to load the settings of chat %d: %v", chatId, err)
+
+		return &settings.ChatSettings{}
+	}
+
+	return chatSettings
+}
+
+// handleSettings shows the settings of the chat, or changes one of them:
+//
+//	/settings language de
+//	/settings categories pun spooky
+//	/settings explicit on
+//	/settings reset
+func (b *Bot) handleSettings(ctx context.Context, request *commands.Request) (*commands.Reply, error) {
+
+	if b.ChatSettings == nil {
+		return nil, ErrNoChatSettingsStore
+	}
+
+	chatSettings, err := b.ChatSettings.Get(ctx, request.ChatId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	args := strings.Fields(request.Invocation.Args)
+
+	if len(args) == 0 {
+		return commands.TextReply(b.describeSettings(chatSettings)), nil
+	}
+
+	if !b.changeSetting(chatSettings, strings.ToLower(args[0]), args[1:]) {
+		return commands.TextReply(b.settingsUsage()), nil
+	}
+
+	if err := b.ChatSettings.Put(ctx, request.ChatId, chatSettings); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Changed the settings of chat %d", request.ChatId)
+
+	return commands.TextReply("Saved! " + b.describeSettings(chatSettings)), nil
+}
+
+// changeSetting applies the change to the settings, telling whether it is a valid one.
+func (b *Bot) changeSetting(chatSettings *settings.ChatSettings, setting string, values []string) bool {
+
+	switch setting {
+
+	case "language":
+		if len(values) != 1 {
+			return false
+		}
+
+		language, found := matchValue(restclient.FactLanguages, values[0])
+
+		if found {
+			chatSettings.Language = language
+		}
+
+		return found
+
+	case "categories":
+		if len(values) == 1 && strings.EqualFold(values[0], anyJokeCategory) {
+			chatSettings.JokeCategories = nil
+			return true
+		}
+
+		var categories []string
+
+		for _, value := range values {
+
+			category, found := matchValue(b.JokeClient.Categories(), value)
+
+			if !found {
+				return false
+			}
+
+			if _, duplicate := matchValue(categories, category); !duplicate {
+				categories = append(categories, category)
+			}
+		}
+
+		chatSettings.JokeCategories = categories
+
+		return len(categories) > 0
+
+	case "explicit":
+		if len(values) != 1 {
+			return false
+		}
+
+		switch strings.ToLower(values[0]) {
+		case "on":
+			chatSettings.AllowExplicit = true
+		case "off":
+			chatSettings.AllowExplicit = false
+		default:
+			return false
+		}
+
+		return true
+
+	case "reset":
+		*chatSettings = settings.ChatSettings{}
+
+		return len(values) == 0
+	}
+
+	return false
+}
+
+func (b *Bot) describeSettings(chatSettings *settings.ChatSettings) string {
+
+	language := chatSettings.Language
+
+	if language == "" {
+		language = restclient.FactLanguages[0]
+	}
+
+	categories := anyJokeCategory
+
+	if len(chatSettings.JokeCategories) > 0 {
+		categories = strings.Join(chatSettings.JokeCategories, ", ")
+	}
+
+	explicit := "off"
+
+	if chatSettings.AllowExplicit {
+		explicit = "on"
+	}
+
+	return fmt.Sprintf("The settings of this chat are:\nLanguage: %s\nJoke categories: %s\nExplicit jokes: %s\n\n%s",
+		language, categories, explicit, b.settingsUsage())
+}
+
+func (b *Bot) settingsUsage() string {
+
+	categories := append(append([]string{}, b.JokeClient.Categories()...), anyJokeCategory)
+
+	return "Change them with:\n" +
+		"/settings language [" + strings.Join(restclient.FactLanguages, "|") + "]\n" +
+		"/settings categories [" + strings.Join(categories, "|") + "]...\n" +
+		"/settings explicit [on|off]\n" +
+		"/settings reset"
+}
+
+// matchValue finds the value regardless of case, returning it as it is in the values.
+func matchValue(values []string, value string) (string, bool) {
+
+	for _, candidate := range values {
+		if strings.EqualFold(candidate, value) {
+			return candidate, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,260 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"my-first-telegram-bot/telegram-handler/dto"
+	"my-first-telegram-bot/telegram-handler/settings"
+	"my-first-telegram-bot/telegram-handler/utils/mocks"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// failingChatSettingsStore fails to load or save any settings.
+type failingChatSettingsStore struct{}
+
+func (failingChatSettingsStore) Get(ctx context.Context, chatId int) (*settings.ChatSettings, error) {
+	return nil, errors.New("table not found")
+}
+
+func (failingChatSettingsStore) Put(ctx context.Context, chatId int, chatSettings *settings.ChatSettings) error {
+	return errors.New("table not found")
+}
+
+func TestSettingsCommand(t *testing.T) {
+
+	scenarios := []struct {
+		name             string
+		saved            settings.ChatSettings
+		text             string
+		expectedSettings settings.ChatSettings
+		expectedReply    string
+	}{
+		{
+			name:             "Show the settings",
+			saved:            settings.ChatSettings{Language: "de", JokeCategories: []string{"pun"}},
+			text:             "/settings",
+			expectedSettings: settings.ChatSettings{Language: "de", JokeCategories: []string{"pun"}},
+			expectedReply:    "The settings of this chat are:\nLanguage: de\nJoke categories: pun\nExplicit jokes: off",
+		},
+		{
+			name:             "Change the language",
+			text:             "/settings language DE",
+			expectedSettings: settings.ChatSettings{Language: "de"},
+			expectedReply:    "Saved! The settings of this chat are:\nLanguage: de\n",
+		},
+		{
+			name:             "Unknown language",
+			text:             "/settings language pt",
+			expectedSettings: settings.ChatSettings{},
+			expectedReply:    "Change them with:\n/settings language [en|de]\n/settings categories [programming|pun|any]...",
+		},
+		{
+			name:             "Prefer joke categories",
+			text:             "/settings categories pun Programming pun",
+			expectedSettings: settings.ChatSettings{JokeCategories: []string{"pun", "programming"}},
+			expectedReply:    "Saved! The settings of this chat are:\nLanguage: en\nJoke categories: pun, programming\n",
+		},
+		{
+			name:             "Unknown joke category",
+			saved:            settings.ChatSettings{JokeCategories: []string{"pun"}},
+			text:             "/settings categories pun dark",
+			expectedSettings: settings.ChatSettings{JokeCategories: []string{"pun"}},
+			expectedReply:    "Change them with:",
+		},
+		{
+			name:             "Any joke category",
+			saved:            settings.ChatSettings{JokeCategories: []string{"pun"}},
+			text:             "/settings categories any",
+			expectedSettings: settings.ChatSettings{},
+			expectedReply:    "Saved! The settings of this chat are:\nLanguage: en\nJoke categories: any\n",
+		},
+		{
+			name:             "Allow explicit jokes",
+			text:             "/settings explicit on",
+			expectedSettings: settings.ChatSettings{AllowExplicit: true},
+			expectedReply:    "Saved! The settings of this chat are:\nLanguage: en\nJoke categories: any\nExplicit jokes: on",
+		},
+		{
+			name:             "Reset",
+			saved:            settings.ChatSettings{Language: "de", AllowExplicit: true},
+			text:             "/settings reset",
+			expectedSettings: settings.ChatSettings{},
+			expectedReply:    "Saved! The settings of this chat are:\nLanguage: en\nJoke categories: any\nExplicit jokes: off",
+		},
+	}
+
+	for _, scenario := range scenarios {
+
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+
+			var sentText string
+
+			// Arrange
+			myMockClient := &mocks.MockBaseClient{JokeCategories: []string{"programming", "pun"}}
+
+			myMockClient.SendMessageFunc = func(message *dto.SendMessageRequest) (*dto.Message, error) {
+				sentText = message.Text
+				return &dto.Message{MessageId: 1, Chat: dto.Chat{Id: message.ChatId}}, nil
+			}
+
+			store := settings.NewMemoryStore()
+
+			saved := scenario.saved
+
+			if err := store.Put(context.Background(), 1234, &saved); err != nil {
+				t.Fatal("Can't run test scenario")
+			}
+
+			myBot := NewBot(myMockClient, myMockClient, myMockClient, Config{})
+			myBot.ChatSettings = store
+
+			// Act
+			myBot.ProcessUpdate(context.Background(), updateBody(t, dto.Update{
+				UpdateId: 1,
+				Message:  dto.Message{Text: scenario.text, Entities: commandEntities(scenario.text), Chat: dto.Chat{Id: 1234}},
+			}))
+
+			// Assert
+
+			chatSettings, err := store.Get(context.Background(), 1234)
+
+			assert.Nil(t, err)
+
+			assert.Equal(t, scenario.expectedSettings, *chatSettings)
+
+			assert.True(t, strings.HasPrefix(sentText, scenario.expectedReply), sentText)
+		})
+	}
+}
+
+func TestChatSettingsAreHonored(t *testing.T) {
+
+	t.Run("Facts and jokes follow the settings of the chat", func(t *testing.T) {
+
+		// Arrange
+		myMockClient := &mocks.MockBaseClient{JokeCategories: []string{"programming", "pun"}}
+
+		myMockClient.GetFactFunc = func() (*dto.GeneratedFact, error) {
+			return &dto.GeneratedFact{Text: "Kartoffeln"}, nil
+		}
+
+		myMockClient.GetJokeFunc = func() (*dto.GeneratedJoke, error) {
+			return &dto.GeneratedJoke{Value: dto.JokeValue{ID: "1", Joke: "Kartoffelwitz"}}, nil
+		}
+
+		myMockClient.SendMessageFunc = func(message *dto.SendMessageRequest) (*dto.Message, error) {
+			return &dto.Message{MessageId: 1, Chat: dto.Chat{Id: message.ChatId}}, nil
+		}
+
+		myBot := NewBot(myMockClient, myMockClient, myMockClient, Config{})
+
+		process := func(updateId int, text string) {
+			myBot.ProcessUpdate(context.Background(), updateBody(t, dto.Update{
+				UpdateId: updateId,
+				Message:  dto.Message{Text: text, Entities: commandEntities(text), Chat: dto.Chat{Id: 1234}},
+			}))
+		}
+
+		// Act
+		process(1, "/settings language de")
+		process(2, "/settings categories pun")
+		process(3, "/fact")
+		process(4, "/joke")
+
+		// Assert
+
+		assert.Equal(t, &dto.FactQuery{Language: "de"}, myMockClient.LastFactQuery)
+
+		assert.Equal(t, &dto.JokeQuery{Category: "pun", Language: "de", SafeMode: true}, myMockClient.LastJokeQuery)
+	})
+
+	t.Run("Defaults are used when the settings can't be loaded", func(t *testing.T) {
+
+		var sentTexts []string
+
+		// Arrange
+		myMockClient := &mocks.MockBaseClient{}
+
+		myMockClient.GetFactFunc = func() (*dto.GeneratedFact, error) {
+			return &dto.GeneratedFact{Text: "potato potato"}, nil
+		}
+
+		myMockClient.SendMessageFunc = func(message *dto.SendMessageRequest) (*dto.Message, error) {
+			sentTexts = append(sentTexts, message.Text)
+			return &dto.Message{MessageId: 1, Chat: dto.Chat{Id: message.ChatId}}, nil
+		}
+
+		myBot := NewBot(myMockClient, myMockClient, myMockClient, Config{})
+		myBot.ChatSettings = failingChatSettingsStore{}
+
+		// Act
+		myBot.ProcessUpdate(context.Background(), updateBody(t, dto.Update{
+			UpdateId: 1,
+			Message:  dto.Message{Text: "/fact", Entities: commandEntities("/fact"), Chat: dto.Chat{Id: 1234}},
+		}))
+
+		myBot.ProcessUpdate(context.Background(), updateBody(t, dto.Update{
+			UpdateId: 2,
+			Message:  dto.Message{Text: "/settings language de", Entities: commandEntities("/settings language de"), Chat: dto.Chat{Id: 1234}},
+		}))
+
+		// Assert
+
+		assert.Equal(t, &dto.FactQuery{}, myMockClient.LastFactQuery)
+
+		assert.Equal(t, []string{"potato potato", FailureReply}, sentTexts)
+	})
+}
+
+// withoutAWSRegion runs the test with no AWS region in the environment.
+func withoutAWSRegion(t *testing.T, test func()) {
+
+	variables := []string{"AWS_REGION", "AWS_DEFAULT_REGION", "AWS_SDK_LOAD_CONFIG"}
+
+	for _, variable := range variables {
+
+		if value, found := os.LookupEnv(variable); found {
+			defer os.Setenv(variable, value)
+		}
+
+		if err := os.Unsetenv(variable); err != nil {
+			t.Fatal("Can't run test scenario")
+		}
+	}
+
+	test()
+}
+
+func TestChatSettingsStoreFromConfig(t *testing.T) {
+
+	t.Run("The bot doesn't start when the chat settings table can't be used", func(t *testing.T) {
+
+		withoutAWSRegion(t, func() {
+
+			// Act
+			myBot, err := NewBotFromConfig(Config{ChatSettingsTable: "ChatSettings", ChatSettingsFile: "settings.json"})
+
+			// Assert
+			assert.Nil(t, myBot)
+
+			assert.True(t, errors.Is(err, settings.ErrNoRegion))
+		})
+	})
+
+	t.Run("The chat settings are kept locally without a table", func(t *testing.T) {
+
+		// Act
+		myBot, err := NewBotFromConfig(Config{ChatSettingsFile: "settings.json"})
+
+		// Assert
+		assert.Nil(t, err)
+
+		assert.Equal(t, settings.NewFileStore("settings.json"), myBot.ChatSettings)
+	})
+}
@@ -0,0 +1,38 @@
+package bot
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+var (
+	// UsernameLookupTimeout bounds looking up the username of the bot when it starts.
+	UsernameLookupTimeout = 5 * time.Second
+)
+
+// LookUpUsernameOnStart asks Telegram for the username of the bot, unless the
+// configuration already has it, so commands ending with @BotName can be told apart
+// from the ones meant for other bots of a group. Failures are only logged: until
+// the username is known, those commands are left to the other bots.
+func (b *Bot) LookUpUsernameOnStart(ctx context.Context) {
+
+	if b.Config.BotUsername != "" || b.IdentityClient == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, UsernameLookupTimeout)
+	defer cancel()
+
+	user, err := b.IdentityClient.GetMeWithContext(ctx)
+
+	if err != nil {
+		log.Printf("Failed to look up the username of the bot, ignoring the commands addressed to a bot: %v", err)
+
+		return
+	}
+
+	log.Printf("The bot is @%s", user.Username)
+
+	b.Config.BotUsername = user.Username
+}
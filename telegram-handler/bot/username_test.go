@@ -0,0 +1,79 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"my-first-telegram-bot/telegram-handler/dto"
+	"my-first-telegram-bot/telegram-handler/utils/mocks"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeIdentityClient struct {
+	user  *dto.User
+	err   error
+	calls int
+}
+
+func (client *fakeIdentityClient) GetMeWithContext(ctx context.Context) (*dto.User, error) {
+
+	client.calls++
+
+	return client.user, client.err
+}
+
+func TestLookUpUsernameOnStart(t *testing.T) {
+
+	scenarios := []struct {
+		name             string
+		configured       string
+		identityClient   *fakeIdentityClient
+		expectedUsername string
+		expectedCalls    int
+	}{
+		{
+			name:             "Username from Telegram",
+			identityClient:   &fakeIdentityClient{user: &dto.User{IsBot: true, Username: "MyDailyFactBot"}},
+			expectedUsername: "MyDailyFactBot",
+			expectedCalls:    1,
+		},
+		{
+			name:             "Configured username",
+			configured:       "MyOtherFactBot",
+			identityClient:   &fakeIdentityClient{user: &dto.User{IsBot: true, Username: "MyDailyFactBot"}},
+			expectedUsername: "MyOtherFactBot",
+			expectedCalls:    0,
+		},
+		{
+			name:             "Telegram unavailable",
+			identityClient:   &fakeIdentityClient{err: errors.New("connection refused")},
+			expectedUsername: "",
+			expectedCalls:    1,
+		},
+	}
+
+	for _, scenario := range scenarios {
+
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+
+			// Arrange
+			myMockClient := &mocks.MockBaseClient{}
+
+			myBot := NewBot(myMockClient, myMockClient, myMockClient, Config{BotUsername: scenario.configured})
+
+			myBot.IdentityClient = scenario.identityClient
+
+			// Act
+			myBot.LookUpUsernameOnStart(context.Background())
+
+			// Assert
+
+			assert.Equal(t, scenario.expectedUsername, myBot.Config.BotUsername)
+
+			assert.Equal(t, scenario.expectedCalls, scenario.identityClient.calls)
+		})
+	}
+}
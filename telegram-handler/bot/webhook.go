@@ -0,0 +1,49 @@
+package bot
+
+import (
+	"crypto/subtle"
+	"errors"
+	"path"
+	"strings"
+)
+
+// SecretTokenHeader carries the secret token given to Telegram when the webhook was set.
+const SecretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+var (
+	ErrInvalidSecretToken = errors.New("the webhook secret token doesn't match")
+	ErrInvalidPathToken   = errors.New("the webhook path token doesn't match")
+)
+
+// verifyWebhookRequest makes sure the request was sent by Telegram, checking the
+// secret token header and the last segment of the path against the configured
+// tokens. Each check is skipped when its token isn't configured.
+func (b *Bot) verifyWebhookRequest(request *WebhookRequest) error {
+
+	if b.Config.WebhookSecretToken != "" && !tokensMatch(request.Header(SecretTokenHeader), b.Config.WebhookSecretToken) {
+		return ErrInvalidSecretToken
+	}
+
+	if b.Config.WebhookPathToken != "" && !tokensMatch(path.Base(request.Path), b.Config.WebhookPathToken) {
+		return ErrInvalidPathToken
+	}
+
+	return nil
+}
+
+// Header looks the header up regardless of the case of its name, since transports
+// like API Gateway forward the header names as the client sent them.
+func (request *WebhookRequest) Header(name string) string {
+
+	for key, value := range request.Headers {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+
+	return ""
+}
+
+func tokensMatch(got string, want string) bool {
+	return subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+}
@@ -0,0 +1,153 @@
+package bot
+
+import (
+	"encoding/json"
+	"my-first-telegram-bot/telegram-handler/dto"
+	"my-first-telegram-bot/telegram-handler/utils/mocks"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandlerWebhookVerification(t *testing.T) {
+
+	config := Config{
+		WebhookSecretToken: "s3cr3t",
+		WebhookPathToken:   "pathToken",
+	}
+
+	scenarios := []struct {
+		name               string
+		request            events.APIGatewayProxyRequest
+		expectedStatusCode int
+	}{
+		{
+			name: "Matching tokens",
+			request: events.APIGatewayProxyRequest{
+				Path:    "/telegram/pathToken",
+				Headers: map[string]string{SecretTokenHeader: "s3cr3t"},
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name: "Lowercase secret token header",
+			request: events.APIGatewayProxyRequest{
+				Path:    "/telegram/pathToken",
+				Headers: map[string]string{"x-telegram-bot-api-secret-token": "s3cr3t"},
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name: "Multi value secret token header",
+			request: events.APIGatewayProxyRequest{
+				Path:              "/telegram/pathToken",
+				MultiValueHeaders: map[string][]string{SecretTokenHeader: {"s3cr3t"}},
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name: "Missing secret token header",
+			request: events.APIGatewayProxyRequest{
+				Path: "/telegram/pathToken",
+			},
+			expectedStatusCode: 401,
+		},
+		{
+			name: "Wrong secret token header",
+			request: events.APIGatewayProxyRequest{
+				Path:    "/telegram/pathToken",
+				Headers: map[string]string{SecretTokenHeader: "batata"},
+			},
+			expectedStatusCode: 401,
+		},
+		{
+			name: "Missing path token",
+			request: events.APIGatewayProxyRequest{
+				Path:    "/telegram",
+				Headers: map[string]string{SecretTokenHeader: "s3cr3t"},
+			},
+			expectedStatusCode: 401,
+		},
+		{
+			name: "Wrong path token",
+			request: events.APIGatewayProxyRequest{
+				Path:    "/telegram/batata",
+				Headers: map[string]string{SecretTokenHeader: "s3cr3t"},
+			},
+			expectedStatusCode: 401,
+		},
+	}
+
+	for _, scenario := range scenarios {
+
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+
+			// Arrange
+			myMockClient := &mocks.MockBaseClient{}
+
+			myMockClient.GetFactFunc = func() (*dto.GeneratedFact, error) {
+				return &dto.GeneratedFact{Text: "fact"}, nil
+			}
+
+			myMockClient.SendMessageFunc = func(message *dto.SendMessageRequest) (*dto.Message, error) {
+				return &dto.Message{MessageId: 1, Chat: dto.Chat{Id: message.ChatId}}, nil
+			}
+
+			requestBody, err := json.Marshal(dto.Update{
+				Message: dto.Message{
+					Text:     "/fact",
+					Entities: commandEntities("/fact"),
+					Chat: dto.Chat{
+						Id: 1234,
+					},
+				},
+				UpdateId: 1,
+			})
+
+			if err != nil {
+				t.Fatal("Can't run test scenario")
+			}
+
+			scenario.request.Body = string(requestBody)
+
+			myBot := NewBot(myMockClient, myMockClient, myMockClient, config)
+
+			// Act
+			response, err := myBot.Handler(scenario.request)
+
+			// Assert
+
+			assert.Nil(t, err)
+
+			assert.EqualValues(t, scenario.expectedStatusCode, response.StatusCode)
+
+			if scenario.expectedStatusCode == 401 {
+
+				assert.Equal(t, Unauthorized, response.Body)
+
+				assert.Equal(t, 0, myMockClient.ReturnGetFactCallCount)
+
+				assert.Equal(t, 0, myMockClient.ReturnSendMessageCallCount)
+			} else {
+
+				assert.Equal(t, 1, myMockClient.ReturnSendMessageCallCount)
+			}
+		})
+	}
+
+	t.Run("Checks are skipped when no tokens are configured", func(t *testing.T) {
+
+		// Arrange
+		myBot := NewBot(&mocks.MockBaseClient{}, &mocks.MockBaseClient{}, &mocks.MockBaseClient{}, Config{})
+
+		// Act
+		err := myBot.verifyWebhookRequest(&WebhookRequest{Path: "/telegram"})
+
+		// Assert
+
+		assert.Nil(t, err)
+	})
+}
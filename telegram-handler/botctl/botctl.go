@@ -0,0 +1,237 @@
+package botctl
+
+import (
+	"fmt"
+	"my-first-telegram-bot/telegram-handler/bot"
+	"my-first-telegram-bot/telegram-handler/dto"
+	"my-first-telegram-bot/telegram-handler/restclient"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+// NewApp creates the command line tool managing the bot through the Telegram API.
+func NewApp() *cli.App {
+	return &cli.App{
+		Name:  "botctl",
+		Usage: "manage the Telegram bot",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "token",
+				Usage:    "the API token of the bot",
+				EnvVars:  []string{"TELEGRAM_API_TOKEN"},
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:    "api-address",
+				Usage:   "the address of the Telegram API, followed by the bot prefix",
+				EnvVars: []string{"TELEGRAM_API_ADDRESS"},
+				Value:   restclient.DefaultTelegramApiAddress,
+			},
+		},
+		Commands: []*cli.Command{
+			webhookCommand(),
+			commandsCommand(),
+		},
+	}
+}
+
+func webhookCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "webhook",
+		Usage: "manage where Telegram delivers the updates of the bot",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "set",
+				Usage:     "deliver the updates to the webhook at the URL",
+				ArgsUsage: " ",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "url",
+						Usage:    "the HTTPS URL of the webhook",
+						EnvVars:  []string{"WEBHOOK_URL"},
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:    "secret-token",
+						Usage:   "the token Telegram sends in the X-Telegram-Bot-Api-Secret-Token header",
+						EnvVars: []string{"TELEGRAM_WEBHOOK_SECRET_TOKEN"},
+					},
+					&cli.StringSliceFlag{
+						Name:  "allowed-updates",
+						Usage: "the kinds of updates to deliver, e.g. message, edited_message or callback_query",
+					},
+					&cli.IntFlag{
+						Name:  "max-connections",
+						Usage: "the most simultaneous connections Telegram opens to the webhook, from 1 to 100",
+					},
+					&cli.BoolFlag{
+						Name:  "drop-pending-updates",
+						Usage: "drop the updates waiting to be delivered",
+					},
+				},
+				Action: setWebhook,
+			},
+			{
+				Name:   "info",
+				Usage:  "show the current webhook and its last errors",
+				Action: showWebhookInfo,
+			},
+			{
+				Name:  "delete",
+				Usage: "stop delivering the updates, so they can be polled for",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "drop-pending-updates",
+						Usage: "drop the updates waiting to be delivered",
+					},
+				},
+				Action: deleteWebhook,
+			},
+		},
+	}
+}
+
+func setWebhook(c *cli.Context) error {
+
+	maxConnections := c.Int("max-connections")
+
+	if c.IsSet("max-connections") && (maxConnections < 1 || maxConnections > 100) {
+		return fmt.Errorf("max-connections must be between 1 and 100, not %d", maxConnections)
+	}
+
+	err := telegramApiClient(c).SetWebhookWithContext(c.Context, &dto.SetWebhookRequest{
+		Url:                c.String("url"),
+		SecretToken:        c.String("secret-token"),
+		AllowedUpdates:     c.StringSlice("allowed-updates"),
+		MaxConnections:     maxConnections,
+		DropPendingUpdates: c.Bool("drop-pending-updates"),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.App.Writer, "Webhook set to %s\n", c.String("url"))
+
+	return nil
+}
+
+func showWebhookInfo(c *cli.Context) error {
+
+	info, err := telegramApiClient(c).GetWebhookInfoWithContext(c.Context)
+
+	if err != nil {
+		return err
+	}
+
+	if info.Url == "" {
+		fmt.Fprintf(c.App.Writer, "No webhook is set, pending updates: %d\n", info.PendingUpdateCount)
+
+		return nil
+	}
+
+	fmt.Fprintf(c.App.Writer, "URL: %s\n", info.Url)
+	fmt.Fprintf(c.App.Writer, "Pending updates: %d\n", info.PendingUpdateCount)
+
+	if info.MaxConnections > 0 {
+		fmt.Fprintf(c.App.Writer, "Max connections: %d\n", info.MaxConnections)
+	}
+
+	if len(info.AllowedUpdates) > 0 {
+		fmt.Fprintf(c.App.Writer, "Allowed updates: %s\n", strings.Join(info.AllowedUpdates, ", "))
+	}
+
+	if info.IpAddress != "" {
+		fmt.Fprintf(c.App.Writer, "IP address: %s\n", info.IpAddress)
+	}
+
+	if info.LastErrorDate > 0 {
+		fmt.Fprintf(c.App.Writer, "Last error: %s at %s\n", info.LastErrorMessage, formatDate(info.LastErrorDate))
+	}
+
+	return nil
+}
+
+func deleteWebhook(c *cli.Context) error {
+
+	err := telegramApiClient(c).DeleteWebhookWithContext(c.Context, &dto.DeleteWebhookRequest{
+		DropPendingUpdates: c.Bool("drop-pending-updates"),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(c.App.Writer, "Webhook deleted")
+
+	return nil
+}
+
+func commandsCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "commands",
+		Usage: "manage the command menus Telegram shows to the users",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "sync",
+				Usage: "publish the command menus derived from the commands of the bot",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "show the menus without publishing them",
+					},
+				},
+				Action: syncCommands,
+			},
+		},
+	}
+}
+
+func syncCommands(c *cli.Context) error {
+
+	myBot, err := bot.NewBotFromConfig(bot.Config{
+		TelegramApiToken:   c.String("token"),
+		TelegramApiAddress: c.String("api-address"),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	for _, menu := range myBot.Menus() {
+
+		language := menu.LanguageCode
+
+		if language == "" {
+			language = "any language"
+		}
+
+		fmt.Fprintf(c.App.Writer, "%s menu in %s:\n", menu.Scope, language)
+
+		for _, command := range menu.Commands {
+			fmt.Fprintf(c.App.Writer, "  /%s - %s\n", command.Command, command.Description)
+		}
+	}
+
+	if c.Bool("dry-run") {
+		return nil
+	}
+
+	if err := myBot.SyncCommands(c.Context); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(c.App.Writer, "Command menus published")
+
+	return nil
+}
+
+func telegramApiClient(c *cli.Context) *restclient.TelegramApiClient {
+	return restclient.NewTelegramApiClient(c.String("api-address"), c.String("token"))
+}
+
+func formatDate(unixTime int) string {
+	return time.Unix(int64(unixTime), 0).UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,218 @@
+package botctl
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type telegramCall struct {
+	path string
+	body map[string]interface{}
+}
+
+// fakeTelegram answers every method call with the same result, recording the calls.
+func fakeTelegram(result string, calls *[]telegramCall) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		var body map[string]interface{}
+
+		json.NewDecoder(r.Body).Decode(&body)
+
+		*calls = append(*calls, telegramCall{path: r.URL.Path, body: body})
+
+		w.Write([]byte("{\"ok\": true,\"result\": " + result + "}"))
+	}))
+}
+
+func runApp(server *httptest.Server, args ...string) (string, error) {
+
+	output := &bytes.Buffer{}
+
+	app := NewApp()
+
+	app.Writer = output
+	app.ErrWriter = output
+
+	err := app.Run(append([]string{"botctl", "--token", "token", "--api-address", server.URL + "/bot"}, args...))
+
+	return output.String(), err
+}
+
+func TestWebhookSet(t *testing.T) {
+
+	t.Run("Webhook is set with its options", func(t *testing.T) {
+
+		var calls []telegramCall
+
+		// Arrange
+		server := fakeTelegram("true", &calls)
+		defer server.Close()
+
+		// Act
+		output, err := runApp(server, "webhook", "set",
+			"--url", "https://example.com/telegram",
+			"--secret-token", "s3cr3t",
+			"--allowed-updates", "message",
+			"--allowed-updates", "callback_query",
+			"--max-connections", "10")
+
+		// Assert
+
+		assert.Nil(t, err)
+
+		assert.Equal(t, "Webhook set to https://example.com/telegram\n", output)
+
+		assert.Equal(t, 1, len(calls))
+
+		assert.Equal(t, "/bottoken/setWebhook", calls[0].path)
+
+		assert.EqualValues(t, map[string]interface{}{
+			"url":             "https://example.com/telegram",
+			"secret_token":    "s3cr3t",
+			"allowed_updates": []interface{}{"message", "callback_query"},
+			"max_connections": float64(10),
+		}, calls[0].body)
+	})
+
+	t.Run("Max connections out of range", func(t *testing.T) {
+
+		var calls []telegramCall
+
+		// Arrange
+		server := fakeTelegram("true", &calls)
+		defer server.Close()
+
+		// Act
+		_, err := runApp(server, "webhook", "set", "--url", "https://example.com/telegram", "--max-connections", "101")
+
+		// Assert
+
+		assert.NotNil(t, err)
+
+		assert.Equal(t, 0, len(calls))
+	})
+}
+
+func TestWebhookInfo(t *testing.T) {
+
+	t.Run("Webhook with errors", func(t *testing.T) {
+
+		var calls []telegramCall
+
+		// Arrange
+		server := fakeTelegram("{\"url\": \"https://example.com/telegram\",\"pending_update_count\": 3,\"last_error_date\": 1615076796,\"last_error_message\": \"Connection refused\",\"max_connections\": 40,\"allowed_updates\": [\"message\"]}", &calls)
+		defer server.Close()
+
+		// Act
+		output, err := runApp(server, "webhook", "info")
+
+		// Assert
+
+		assert.Nil(t, err)
+
+		assert.Equal(t, "/bottoken/getWebhookInfo", calls[0].path)
+
+		assert.Equal(t, "URL: https://example.com/telegram\n"+
+			"Pending updates: 3\n"+
+			"Max connections: 40\n"+
+			"Allowed updates: message\n"+
+			"Last error: Connection refused at 2021-03-07T00:26:36Z\n", output)
+	})
+
+	t.Run("No webhook", func(t *testing.T) {
+
+		var calls []telegramCall
+
+		// Arrange
+		server := fakeTelegram("{\"url\": \"\",\"pending_update_count\": 0}", &calls)
+		defer server.Close()
+
+		// Act
+		output, err := runApp(server, "webhook", "info")
+
+		// Assert
+
+		assert.Nil(t, err)
+
+		assert.Equal(t, "No webhook is set, pending updates: 0\n", output)
+	})
+}
+
+func TestWebhookDelete(t *testing.T) {
+
+	t.Run("Webhook is deleted", func(t *testing.T) {
+
+		var calls []telegramCall
+
+		// Arrange
+		server := fakeTelegram("true", &calls)
+		defer server.Close()
+
+		// Act
+		output, err := runApp(server, "webhook", "delete", "--drop-pending-updates")
+
+		// Assert
+
+		assert.Nil(t, err)
+
+		assert.Equal(t, "Webhook deleted\n", output)
+
+		assert.Equal(t, "/bottoken/deleteWebhook", calls[0].path)
+
+		assert.EqualValues(t, map[string]interface{}{"drop_pending_updates": true}, calls[0].body)
+	})
+}
+
+func TestCommandsSync(t *testing.T) {
+
+	t.Run("Menus are published", func(t *testing.T) {
+
+		var calls []telegramCall
+
+		// Arrange
+		server := fakeTelegram("true", &calls)
+		defer server.Close()
+
+		// Act
+		output, err := runApp(server, "commands", "sync")
+
+		// Assert
+
+		assert.Nil(t, err)
+
+		assert.Contains(t, output, "default menu in de:\n  /fact - Eine zufällige nutzlose Tatsache\n")
+
+		assert.Contains(t, output, "Command menus published\n")
+
+		assert.Equal(t, 2, len(calls))
+
+		assert.Equal(t, "/bottoken/setMyCommands", calls[0].path)
+
+		assert.Equal(t, "de", calls[1].body["language_code"])
+	})
+
+	t.Run("Dry run", func(t *testing.T) {
+
+		var calls []telegramCall
+
+		// Arrange
+		server := fakeTelegram("true", &calls)
+		defer server.Close()
+
+		// Act
+		output, err := runApp(server, "commands", "sync", "--dry-run")
+
+		// Assert
+
+		assert.Nil(t, err)
+
+		assert.Contains(t, output, "default menu in any language:\n  /fact - Get a random useless fact\n")
+
+		assert.Equal(t, 0, len(calls))
+	})
+}
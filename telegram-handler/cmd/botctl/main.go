@@ -0,0 +1,14 @@
+package main
+
+import (
+	"log"
+	"my-first-telegram-bot/telegram-handler/botctl"
+	"os"
+)
+
+func main() {
+
+	if err := botctl.NewApp().Run(os.Args); err != nil {
+		log.Fatal(err)
+	}
+}
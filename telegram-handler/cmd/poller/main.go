@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"my-first-telegram-bot/telegram-handler/bot"
+	"my-first-telegram-bot/telegram-handler/poller"
+	"my-first-telegram-bot/telegram-handler/restclient"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// The poller runs the bot locally by fetching its updates with getUpdates.
+// It takes the same environment variables as the Lambda function, where
+// TELEGRAM_API_ADDRESS points it to a fake Telegram server instead of the real
+// one, e.g. http://localhost:8081/bot.
+func main() {
+
+	config := bot.ConfigFromEnv()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	myBot, err := bot.NewBotFromConfig(config)
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	myBot.LookUpUsernameOnStart(ctx)
+	myBot.SyncCommandsOnStart(ctx)
+
+	updatesPoller := poller.NewPoller(
+		restclient.NewTelegramUpdatesClient(config.TelegramApiAddress, config.TelegramApiToken),
+		myBot,
+		restclient.SystemClock)
+
+	log.Printf("Polling %s for updates", config.TelegramApiAddress)
+
+	if err := updatesPoller.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		log.Fatal(err)
+	}
+}
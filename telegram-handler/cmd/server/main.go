@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"log"
+	"my-first-telegram-bot/telegram-handler/bot"
+	"my-first-telegram-bot/telegram-handler/server"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// The server runs the bot as a plain HTTP server taking the Telegram webhook
+// requests, for hosting it outside of Lambda. It takes the same environment
+// variables as the Lambda function, plus LISTEN_ADDRESS, WEBHOOK_PATH and
+// SHUTDOWN_DRAIN_DELAY.
+func main() {
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	myBot, err := bot.NewBotFromConfig(bot.ConfigFromEnv())
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	myBot.LookUpUsernameOnStart(ctx)
+	myBot.SyncCommandsOnStart(ctx)
+
+	webhookServer := server.NewServer(myBot, server.ConfigFromEnv())
+
+	if err := webhookServer.Run(ctx); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,293 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"my-first-telegram-bot/telegram-handler/dto"
+	"sort"
+	"strings"
+	"unicode/utf16"
+)
+
+const (
+	commandPrefix    = "/"
+	botNameSeparator = "@"
+
+	// MaxCommandLength is the maximum length Telegram accepts for a bot command name.
+	MaxCommandLength = 32
+)
+
+var (
+	ErrEmptyCommandName   = errors.New("Command name can't be empty")
+	ErrInvalidCommandName = errors.New("Command names can only contain lowercase latin letters, digits and underscores")
+	ErrMissingHandler     = errors.New("Command has no handler")
+	ErrDuplicateCommand   = errors.New("Command name or alias already registered")
+)
+
+// HandlerFunc produces the reply that is sent back to the chat for a given request.
+// The context is done when the command runs out of time.
+type HandlerFunc func(ctx context.Context, request *Request) (*Reply, error)
+
+// Reply is the message a command answers with. ParseMode and ReplyMarkup
+// follow the Telegram sendMessage semantics.
+type Reply struct {
+	Text                  string
+	ParseMode             string
+	DisableWebPagePreview bool
+	ReplyMarkup           interface{}
+	// FollowUp completes the reply in a second message, like the punchline of a
+	// two part joke. When and how it is sent is up to the bot.
+	FollowUp *Reply
+	// ItemId identifies the content of the reply, like the id of a fact, so the bot
+	// can avoid sending the same content to a chat twice. Empty when it can repeat.
+	ItemId string
+}
+
+// TextReply creates a plain text reply.
+func TextReply(text string) *Reply {
+	return &Reply{
+		Text: text,
+	}
+}
+
+type refetchContextKey struct{}
+
+// NewRefetchContext marks the context of a command run again because its reply
+// repeated content the chat already got.
+func NewRefetchContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, refetchContextKey{}, true)
+}
+
+// IsRefetch tells whether the command runs again because of a repeated reply, so
+// it can look for its content elsewhere.
+func IsRefetch(ctx context.Context) bool {
+
+	refetch, _ := ctx.Value(refetchContextKey{}).(bool)
+
+	return refetch
+}
+
+// Request carries all the state scoped to a single Telegram update, so
+// updates can be processed concurrently without sharing anything.
+type Request struct {
+	Update     *dto.Update
+	ChatId     int
+	User       *dto.User
+	Invocation *Invocation
+}
+
+// Sender identifies who sent the request in logs.
+func (r *Request) Sender() string {
+
+	if r.User != nil {
+		return r.User.String()
+	}
+
+	if chat := r.Update.EffectiveChat(); chat != nil && len(chat.Title) > 0 {
+		return chat.Title
+	}
+
+	return "unknown sender"
+}
+
+// Command describes a bot command and how to handle it.
+type Command struct {
+	Name        string
+	Aliases     []string
+	Description string
+	Handler     HandlerFunc
+	// Descriptions translate the description for the command menus, by language code.
+	Descriptions map[string]string
+	// Scopes limits the command to the menus of these scope types, like
+	// dto.BotCommandScopeAllGroupChats. Commands without scopes are in every menu.
+	Scopes []string
+	// WebhookReply answers the command in the webhook response instead of calling
+	// the Telegram API, saving a round trip. Telegram doesn't report whether such
+	// replies fail, so it suits commands whose replies are cheap to lose.
+	WebhookReply bool
+	// FollowUp fetches the follow up of a reply again by the item id of the reply,
+	// for bots sending it later. Nil when the command can't.
+	FollowUp func(ctx context.Context, itemId string) (*Reply, error)
+}
+
+// Invocation is a bot command parsed out of a message text.
+type Invocation struct {
+	Name    string
+	BotName string
+	Args    string
+}
+
+// Registry keeps track of the commands the bot knows how to handle.
+type Registry struct {
+	commands map[string]*Command
+	ordered  []*Command
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		commands: map[string]*Command{},
+	}
+}
+
+// Register adds a command, reachable by its name and every alias, to the registry.
+func (r *Registry) Register(command *Command) error {
+
+	if command.Handler == nil {
+		return ErrMissingHandler
+	}
+
+	names := append([]string{command.Name}, command.Aliases...)
+
+	for _, name := range names {
+
+		if err := validateName(name); err != nil {
+			return err
+		}
+
+		if _, found := r.commands[name]; found {
+			return ErrDuplicateCommand
+		}
+	}
+
+	for _, name := range names {
+		r.commands[name] = command
+	}
+
+	r.ordered = append(r.ordered, command)
+
+	return nil
+}
+
+// MustRegister is like Register but panics if the command can't be registered.
+func (r *Registry) MustRegister(command *Command) {
+
+	if err := r.Register(command); err != nil {
+		panic(command.Name + ": " + err.Error())
+	}
+}
+
+// Lookup finds a command by its name or by one of its aliases.
+func (r *Registry) Lookup(name string) (*Command, bool) {
+
+	command, found := r.commands[strings.ToLower(name)]
+
+	return command, found
+}
+
+// Commands returns the registered commands sorted by name.
+func (r *Registry) Commands() []*Command {
+
+	sorted := make([]*Command, len(r.ordered))
+	copy(sorted, r.ordered)
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	return sorted
+}
+
+// ParseMessage extracts the bot command the message starts with. Telegram marks
+// commands with a bot_command entity, so only a message whose content starts with
+// one carries a command: the command is the text of the entity, and the rest of
+// the content its arguments.
+func ParseMessage(message *dto.Message) (*Invocation, bool) {
+
+	content := utf16.Encode([]rune(message.Content()))
+
+	for _, entity := range message.ContentEntities() {
+
+		if entity.Type != dto.EntityTypeBotCommand || entity.Offset != 0 || entity.Length > len(content) {
+			continue
+		}
+
+		invocation, ok := Parse(string(utf16.Decode(content[:entity.Length])))
+
+		if !ok {
+			return nil, false
+		}
+
+		invocation.Args = strings.TrimSpace(string(utf16.Decode(content[entity.Length:])))
+
+		return invocation, true
+	}
+
+	return nil, false
+}
+
+// Parse extracts a bot command from a message text following Telegram's rules:
+// the text has to start with a slash, the command name can be followed by
+// @BotName and anything after the first whitespace is considered arguments.
+func Parse(text string) (*Invocation, bool) {
+
+	if !strings.HasPrefix(text, commandPrefix) {
+		return nil, false
+	}
+
+	command := text[len(commandPrefix):]
+	args := ""
+
+	if index := strings.IndexAny(command, " \t\n"); index >= 0 {
+		args = strings.TrimSpace(command[index+1:])
+		command = command[:index]
+	}
+
+	botName := ""
+
+	if index := strings.Index(command, botNameSeparator); index >= 0 {
+		botName = command[index+len(botNameSeparator):]
+		command = command[:index]
+	}
+
+	command = strings.ToLower(command)
+
+	if validateName(command) != nil {
+		return nil, false
+	}
+
+	return &Invocation{
+		Name:    command,
+		BotName: botName,
+		Args:    args,
+	}, true
+}
+
+// IsAddressedTo reports whether the invocation is meant for the bot with the given username.
+// Commands without an explicit @BotName suffix are addressed to every bot in the chat. While
+// the username is unknown, commands with one are taken as meant for another bot.
+func (i *Invocation) IsAddressedTo(botName string) bool {
+
+	if i.BotName == "" {
+		return true
+	}
+
+	if botName == "" {
+		return false
+	}
+
+	return strings.EqualFold(i.BotName, strings.TrimPrefix(botName, botNameSeparator))
+}
+
+// IsExplicitlyAddressedTo reports whether the invocation ends with the @BotName of the bot.
+func (i *Invocation) IsExplicitlyAddressedTo(botName string) bool {
+	return i.BotName != "" && botName != "" && i.IsAddressedTo(botName)
+}
+
+func validateName(name string) error {
+
+	if len(name) == 0 {
+		return ErrEmptyCommandName
+	}
+
+	if len(name) > MaxCommandLength {
+		return ErrInvalidCommandName
+	}
+
+	for _, c := range name {
+		if !(c >= 'a' && c <= 'z') && !(c >= '0' && c <= '9') && c != '_' {
+			return ErrInvalidCommandName
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,175 @@
+package commands
+
+import (
+	"context"
+	"my-first-telegram-bot/telegram-handler/dto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func noopHandler(ctx context.Context, request *Request) (*Reply, error) {
+	return TextReply(""), nil
+}
+
+func TestParse(t *testing.T) {
+
+	t.Run("Parse command texts", func(t *testing.T) {
+
+		scenarios := []struct {
+			text     string
+			expected *Invocation
+		}{
+			{"/fact", &Invocation{Name: "fact"}},
+			{"/FACT", &Invocation{Name: "fact"}},
+			{"/fact@MyDailyFactBot", &Invocation{Name: "fact", BotName: "MyDailyFactBot"}},
+			{"/joke  nerdy  stuff ", &Invocation{Name: "joke", Args: "nerdy  stuff"}},
+			{"/joke@MyDailyFactBot nerdy", &Invocation{Name: "joke", BotName: "MyDailyFactBot", Args: "nerdy"}},
+			{"/jokefact", &Invocation{Name: "jokefact"}},
+			{"tell me a /fact", nil},
+			{"", nil},
+			{"/", nil},
+			{"/fact-now", nil},
+		}
+
+		for _, scenario := range scenarios {
+
+			// Act
+			invocation, ok := Parse(scenario.text)
+
+			// Assert
+			assert.Equal(t, scenario.expected != nil, ok, scenario.text)
+
+			assert.EqualValues(t, scenario.expected, invocation, scenario.text)
+		}
+	})
+}
+
+func TestParseMessage(t *testing.T) {
+
+	t.Run("Commands are found by their bot_command entity", func(t *testing.T) {
+
+		command := func(length int) []dto.MessageEntity {
+			return []dto.MessageEntity{{Type: dto.EntityTypeBotCommand, Length: length}}
+		}
+
+		scenarios := []struct {
+			name     string
+			message  dto.Message
+			expected *Invocation
+		}{
+			{
+				name:     "Command with arguments",
+				message:  dto.Message{Text: "/joke@MyDailyFactBot pun", Entities: command(20)},
+				expected: &Invocation{Name: "joke", BotName: "MyDailyFactBot", Args: "pun"},
+			},
+			{
+				name:     "Command in a caption",
+				message:  dto.Message{Caption: "/fact", CaptionEntities: command(5)},
+				expected: &Invocation{Name: "fact"},
+			},
+			{
+				name:     "Arguments after emoji",
+				message:  dto.Message{Text: "/joke 😀 pun", Entities: command(5)},
+				expected: &Invocation{Name: "joke", Args: "😀 pun"},
+			},
+			{
+				name:    "Text without entities",
+				message: dto.Message{Text: "/fact"},
+			},
+			{
+				name:    "Command in the middle of the text",
+				message: dto.Message{Text: "tell me a /fact", Entities: []dto.MessageEntity{{Type: dto.EntityTypeBotCommand, Offset: 10, Length: 5}}},
+			},
+			{
+				name:    "Other entity at the start",
+				message: dto.Message{Text: "/fact", Entities: []dto.MessageEntity{{Type: "code", Length: 5}}},
+			},
+		}
+
+		for _, scenario := range scenarios {
+
+			// Act
+			invocation, ok := ParseMessage(&scenario.message)
+
+			// Assert
+			assert.Equal(t, scenario.expected != nil, ok, scenario.name)
+
+			assert.EqualValues(t, scenario.expected, invocation, scenario.name)
+		}
+	})
+}
+
+func TestIsAddressedTo(t *testing.T) {
+
+	t.Run("Invocation addressed to bot", func(t *testing.T) {
+
+		assert.True(t, (&Invocation{Name: "fact"}).IsAddressedTo("MyDailyFactBot"))
+
+		assert.True(t, (&Invocation{Name: "fact", BotName: "mydailyfactbot"}).IsAddressedTo("@MyDailyFactBot"))
+
+		assert.False(t, (&Invocation{Name: "fact", BotName: "OtherBot"}).IsAddressedTo(""))
+
+		assert.False(t, (&Invocation{Name: "fact", BotName: "OtherBot"}).IsAddressedTo("MyDailyFactBot"))
+
+		assert.True(t, (&Invocation{Name: "fact", BotName: "mydailyfactbot"}).IsExplicitlyAddressedTo("MyDailyFactBot"))
+
+		assert.False(t, (&Invocation{Name: "fact"}).IsExplicitlyAddressedTo("MyDailyFactBot"))
+
+		assert.False(t, (&Invocation{Name: "fact", BotName: "OtherBot"}).IsExplicitlyAddressedTo(""))
+	})
+}
+
+func TestRegistry(t *testing.T) {
+
+	t.Run("Register and lookup commands", func(t *testing.T) {
+
+		// Arrange
+		registry := NewRegistry()
+
+		help := &Command{Name: "help", Aliases: []string{"start"}, Handler: noopHandler}
+		fact := &Command{Name: "fact", Handler: noopHandler}
+
+		// Act
+		assert.Nil(t, registry.Register(help))
+		assert.Nil(t, registry.Register(fact))
+
+		// Assert
+		command, found := registry.Lookup("start")
+
+		assert.True(t, found)
+		assert.Equal(t, help, command)
+
+		command, found = registry.Lookup("FACT")
+
+		assert.True(t, found)
+		assert.Equal(t, fact, command)
+
+		_, found = registry.Lookup("joke")
+
+		assert.False(t, found)
+
+		assert.Equal(t, []*Command{fact, help}, registry.Commands())
+	})
+
+	t.Run("Reject invalid commands", func(t *testing.T) {
+
+		// Arrange
+		registry := NewRegistry()
+
+		registry.MustRegister(&Command{Name: "fact", Handler: noopHandler})
+
+		// Act & Assert
+		assert.Equal(t, ErrDuplicateCommand, registry.Register(&Command{Name: "trivia", Aliases: []string{"fact"}, Handler: noopHandler}))
+
+		assert.Equal(t, ErrInvalidCommandName, registry.Register(&Command{Name: "Joke", Handler: noopHandler}))
+
+		assert.Equal(t, ErrEmptyCommandName, registry.Register(&Command{Handler: noopHandler}))
+
+		assert.Equal(t, ErrMissingHandler, registry.Register(&Command{Name: "joke"}))
+
+		_, found := registry.Lookup("trivia")
+
+		assert.False(t, found)
+	})
+}
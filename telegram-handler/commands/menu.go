@@ -0,0 +1,122 @@
+package commands
+
+import (
+	"my-first-telegram-bot/telegram-handler/dto"
+	"sort"
+)
+
+// Menu is the list of commands Telegram shows to the users of a scope speaking a language.
+type Menu struct {
+	// Scope is the scope type of the menu, dto.BotCommandScopeDefault for everyone.
+	Scope string
+	// LanguageCode is the language of the descriptions, empty for the users of any other language.
+	LanguageCode string
+	Commands     []dto.BotCommand
+}
+
+// Menus derives the command menus from the registered commands: one for every
+// scope some command is limited to, in every language some command is translated
+// to. Telegram shows the most specific menu only, so the menus of a scope also
+// list the commands every scope has.
+func (r *Registry) Menus() []*Menu {
+
+	scopes := []string{dto.BotCommandScopeDefault}
+	languages := []string{""}
+
+	seenScopes := map[string]bool{dto.BotCommandScopeDefault: true}
+	seenLanguages := map[string]bool{"": true}
+
+	for _, command := range r.ordered {
+
+		for _, scope := range command.Scopes {
+			if !seenScopes[scope] {
+				seenScopes[scope] = true
+				scopes = append(scopes, scope)
+			}
+		}
+
+		for language := range command.Descriptions {
+			if !seenLanguages[language] {
+				seenLanguages[language] = true
+				languages = append(languages, language)
+			}
+		}
+	}
+
+	sort.Strings(scopes[1:])
+	sort.Strings(languages[1:])
+
+	var menus []*Menu
+
+	for _, scope := range scopes {
+		for _, language := range languages {
+			menus = append(menus, &Menu{
+				Scope:        scope,
+				LanguageCode: language,
+				Commands:     r.menuCommands(scope, language),
+			})
+		}
+	}
+
+	return menus
+}
+
+func (r *Registry) menuCommands(scope string, language string) []dto.BotCommand {
+
+	var menuCommands []dto.BotCommand
+
+	for _, command := range r.Commands() {
+
+		if !command.inScope(scope) {
+			continue
+		}
+
+		description, translated := command.Descriptions[language]
+
+		if !translated {
+			description = command.Description
+		}
+
+		menuCommands = append(menuCommands, dto.BotCommand{
+			Command:     command.Name,
+			Description: description,
+		})
+	}
+
+	return menuCommands
+}
+
+// inScope tells whether the command is in the menus of the scope.
+func (c *Command) inScope(scope string) bool {
+
+	if len(c.Scopes) == 0 {
+		return true
+	}
+
+	for _, commandScope := range c.Scopes {
+		if commandScope == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SetMyCommandsRequest is the setMyCommands call publishing the menu.
+func (m *Menu) SetMyCommandsRequest() *dto.SetMyCommandsRequest {
+
+	request := &dto.SetMyCommandsRequest{
+		Commands:     m.Commands,
+		LanguageCode: m.LanguageCode,
+	}
+
+	if m.Scope != dto.BotCommandScopeDefault {
+		request.Scope = &dto.BotCommandScope{Type: m.Scope}
+	}
+
+	if request.Commands == nil {
+		request.Commands = []dto.BotCommand{}
+	}
+
+	return request
+}
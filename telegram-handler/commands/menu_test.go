@@ -0,0 +1,103 @@
+package commands
+
+import (
+	"my-first-telegram-bot/telegram-handler/dto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMenus(t *testing.T) {
+
+	t.Run("Menus by scope and language", func(t *testing.T) {
+
+		// Arrange
+		registry := NewRegistry()
+
+		registry.MustRegister(&Command{
+			Name:         "help",
+			Aliases:      []string{"start"},
+			Description:  "List the commands",
+			Descriptions: map[string]string{"de": "Befehle auflisten"},
+			Handler:      noopHandler,
+		})
+
+		registry.MustRegister(&Command{
+			Name:        "settings",
+			Description: "Change the chat settings",
+			Scopes:      []string{dto.BotCommandScopeAllChatAdministrators},
+			Handler:     noopHandler,
+		})
+
+		registry.MustRegister(&Command{
+			Name:        "fact",
+			Description: "Get a fact",
+			Handler:     noopHandler,
+		})
+
+		// Act
+		menus := registry.Menus()
+
+		// Assert
+		assert.Equal(t, []*Menu{
+			{
+				Scope: dto.BotCommandScopeDefault,
+				Commands: []dto.BotCommand{
+					{Command: "fact", Description: "Get a fact"},
+					{Command: "help", Description: "List the commands"},
+				},
+			},
+			{
+				Scope:        dto.BotCommandScopeDefault,
+				LanguageCode: "de",
+				Commands: []dto.BotCommand{
+					{Command: "fact", Description: "Get a fact"},
+					{Command: "help", Description: "Befehle auflisten"},
+				},
+			},
+			{
+				Scope: dto.BotCommandScopeAllChatAdministrators,
+				Commands: []dto.BotCommand{
+					{Command: "fact", Description: "Get a fact"},
+					{Command: "help", Description: "List the commands"},
+					{Command: "settings", Description: "Change the chat settings"},
+				},
+			},
+			{
+				Scope:        dto.BotCommandScopeAllChatAdministrators,
+				LanguageCode: "de",
+				Commands: []dto.BotCommand{
+					{Command: "fact", Description: "Get a fact"},
+					{Command: "help", Description: "Befehle auflisten"},
+					{Command: "settings", Description: "Change the chat settings"},
+				},
+			},
+		}, menus)
+	})
+
+	t.Run("Menus as setMyCommands calls", func(t *testing.T) {
+
+		// Arrange
+		defaultMenu := &Menu{Scope: dto.BotCommandScopeDefault}
+
+		groupMenu := &Menu{
+			Scope:        dto.BotCommandScopeAllGroupChats,
+			LanguageCode: "de",
+			Commands:     []dto.BotCommand{{Command: "fact", Description: "Get a fact"}},
+		}
+
+		// Act
+		defaultRequest := defaultMenu.SetMyCommandsRequest()
+
+		groupRequest := groupMenu.SetMyCommandsRequest()
+
+		// Assert
+		assert.Equal(t, &dto.SetMyCommandsRequest{Commands: []dto.BotCommand{}}, defaultRequest)
+
+		assert.Equal(t, &dto.SetMyCommandsRequest{
+			Commands:     []dto.BotCommand{{Command: "fact", Description: "Get a fact"}},
+			Scope:        &dto.BotCommandScope{Type: dto.BotCommandScopeAllGroupChats},
+			LanguageCode: "de",
+		}, groupRequest)
+	})
+}
@@ -0,0 +1,128 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrInvalidArguments = errors.New("Invalid command arguments")
+
+// ContentProvider fetches content from a source, like facts or jokes, and renders it as a reply.
+type ContentProvider interface {
+	// Name is the name of the command serving the content.
+	Name() string
+	// Fetch gets a piece of content, narrowed down by the arguments of the command.
+	Fetch(ctx context.Context, args string) (*Reply, error)
+}
+
+// Parameter is an optional argument of a command, taking one of the values it advertises.
+type Parameter struct {
+	Name   string
+	Values []string
+}
+
+// ParameterizedProvider is a ContentProvider narrowing its content down by the
+// arguments of the command, which are checked against its parameters before
+// anything is fetched.
+type ParameterizedProvider interface {
+	ContentProvider
+	Parameters() []Parameter
+}
+
+// FollowUpProvider is a ContentProvider that can fetch the follow up of the content
+// it served again, by the item id of its reply.
+type FollowUpProvider interface {
+	ContentProvider
+	FetchFollowUp(ctx context.Context, itemId string) (*Reply, error)
+}
+
+// Arguments are the values picked for the parameters of a command, by parameter name.
+type Arguments map[string]string
+
+// ParseArguments matches each word of the arguments with the parameter advertising
+// it, regardless of case and order. Every parameter takes one value at most.
+func ParseArguments(parameters []Parameter, args string) (Arguments, error) {
+
+	arguments := Arguments{}
+
+	for _, word := range strings.Fields(args) {
+
+		parameter, value, found := matchParameter(parameters, arguments, word)
+
+		if !found {
+			return nil, fmt.Errorf("%w: unexpected %q", ErrInvalidArguments, word)
+		}
+
+		arguments[parameter] = value
+	}
+
+	return arguments, nil
+}
+
+func matchParameter(parameters []Parameter, picked Arguments, word string) (string, string, bool) {
+
+	for _, parameter := range parameters {
+
+		if _, found := picked[parameter.Name]; found {
+			continue
+		}
+
+		for _, value := range parameter.Values {
+			if strings.EqualFold(value, word) {
+				return parameter.Name, value, true
+			}
+		}
+	}
+
+	return "", "", false
+}
+
+// Usage describes how to call the command with its parameters, like "/fact [today|random] [en|de]".
+func Usage(name string, parameters []Parameter) string {
+
+	usage := commandPrefix + name
+
+	for _, parameter := range parameters {
+		if len(parameter.Values) > 0 {
+			usage += " [" + strings.Join(parameter.Values, "|") + "]"
+		}
+	}
+
+	return usage
+}
+
+// ProviderCommand creates the command serving the content of the provider. When
+// the arguments don't match the parameters of the provider, the command answers
+// with its usage instead.
+func ProviderCommand(provider ContentProvider, description string) *Command {
+
+	command := &Command{
+		Name:        provider.Name(),
+		Description: description,
+		Handler: func(ctx context.Context, request *Request) (*Reply, error) {
+
+			args, name := "", provider.Name()
+
+			if request.Invocation != nil {
+				args, name = request.Invocation.Args, request.Invocation.Name
+			}
+
+			if parameterized, ok := provider.(ParameterizedProvider); ok {
+
+				if _, err := ParseArguments(parameterized.Parameters(), args); err != nil {
+					return TextReply("Usage: " + Usage(name, parameterized.Parameters())), nil
+				}
+			}
+
+			return provider.Fetch(ctx, args)
+		},
+	}
+
+	if followUpProvider, ok := provider.(FollowUpProvider); ok {
+		command.FollowUp = followUpProvider.FetchFollowUp
+	}
+
+	return command
+}
@@ -0,0 +1,165 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type echoProvider struct{}
+
+func (echoProvider) Name() string {
+	return "echo"
+}
+
+func (echoProvider) Fetch(ctx context.Context, args string) (*Reply, error) {
+	return TextReply("echo: " + args), nil
+}
+
+// colorProvider echoes the color and size picked in the arguments.
+type colorProvider struct{}
+
+func (colorProvider) Name() string {
+	return "color"
+}
+
+func (colorProvider) Parameters() []Parameter {
+	return []Parameter{
+		{Name: "color", Values: []string{"red", "green"}},
+		{Name: "size", Values: []string{"small", "large"}},
+	}
+}
+
+func (p colorProvider) Fetch(ctx context.Context, args string) (*Reply, error) {
+
+	arguments, err := ParseArguments(p.Parameters(), args)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return TextReply(arguments["size"] + " " + arguments["color"]), nil
+}
+
+// riddleProvider answers with a riddle, followed up by its answer.
+type riddleProvider struct{}
+
+func (riddleProvider) Name() string {
+	return "riddle"
+}
+
+func (riddleProvider) Fetch(ctx context.Context, args string) (*Reply, error) {
+	return &Reply{Text: "What has keys but can't open locks?", FollowUp: TextReply("A piano"), ItemId: "piano"}, nil
+}
+
+func (riddleProvider) FetchFollowUp(ctx context.Context, itemId string) (*Reply, error) {
+	return TextReply("The answer to " + itemId), nil
+}
+
+func TestProviderCommand(t *testing.T) {
+
+	t.Run("Provider commands pass their arguments on", func(t *testing.T) {
+
+		// Arrange
+		command := ProviderCommand(echoProvider{}, "Repeat the arguments")
+
+		// Act
+		reply, err := command.Handler(context.Background(), &Request{Invocation: &Invocation{Name: "echo", Args: "batata frita"}})
+
+		// Assert
+		assert.Nil(t, err)
+
+		assert.Equal(t, "echo", command.Name)
+
+		assert.Equal(t, "Repeat the arguments", command.Description)
+
+		assert.Equal(t, "echo: batata frita", reply.Text)
+
+		assert.Nil(t, command.FollowUp)
+	})
+
+	t.Run("Follow ups are fetched again through the provider", func(t *testing.T) {
+
+		// Arrange
+		command := ProviderCommand(riddleProvider{}, "Ask a riddle")
+
+		// Act
+		followUp, err := command.FollowUp(context.Background(), "piano")
+
+		// Assert
+		assert.Nil(t, err)
+
+		assert.Equal(t, "The answer to piano", followUp.Text)
+	})
+
+	t.Run("Arguments are checked against the parameters of the provider", func(t *testing.T) {
+
+		// Arrange
+		command := ProviderCommand(colorProvider{}, "Show a color")
+
+		// Act
+		reply, err := command.Handler(context.Background(), &Request{Invocation: &Invocation{Name: "colour", Args: "LARGE red"}})
+
+		badReply, badErr := command.Handler(context.Background(), &Request{Invocation: &Invocation{Name: "colour", Args: "red blue"}})
+
+		// Assert
+		assert.Nil(t, err)
+
+		assert.Equal(t, "large red", reply.Text)
+
+		assert.Nil(t, badErr)
+
+		assert.Equal(t, "Usage: /colour [red|green] [small|large]", badReply.Text)
+	})
+}
+
+func TestParseArguments(t *testing.T) {
+
+	parameters := colorProvider{}.Parameters()
+
+	scenarios := []struct {
+		name              string
+		args              string
+		expectedArguments Arguments
+		expectedInvalid   bool
+	}{
+		{
+			name:              "No arguments",
+			args:              "",
+			expectedArguments: Arguments{},
+		},
+		{
+			name:              "Arguments in any order and case",
+			args:              " Small  GREEN ",
+			expectedArguments: Arguments{"color": "green", "size": "small"},
+		},
+		{
+			name:            "Unknown value",
+			args:            "blue",
+			expectedInvalid: true,
+		},
+		{
+			name:            "Parameter picked twice",
+			args:            "red green",
+			expectedInvalid: true,
+		},
+	}
+
+	for _, scenario := range scenarios {
+
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+
+			// Act
+			arguments, err := ParseArguments(parameters, scenario.args)
+
+			// Assert
+			assert.Equal(t, scenario.expectedInvalid, errors.Is(err, ErrInvalidArguments))
+
+			assert.Equal(t, scenario.expectedArguments, arguments)
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package dedup
+
+import (
+	"my-first-telegram-bot/telegram-handler/internal/jsonfile"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Store remembers which updates were already handled, so a redelivered
+// webhook is processed at most once.
+type Store interface {
+	// MarkSeen records the update id and tells whether it is the first time it is seen.
+	MarkSeen(updateId int) (bool, error)
+}
+
+// MemoryStore keeps the update ids in memory for the given time to live.
+type MemoryStore struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	seen      map[int]time.Time
+	lastPrune time.Time
+	now       func() time.Time
+}
+
+func NewMemoryStore(ttl time.Duration) *MemoryStore {
+	return &MemoryStore{
+		ttl:  ttl,
+		seen: map[int]time.Time{},
+		now:  time.Now,
+	}
+}
+
+func (s *MemoryStore) MarkSeen(updateId int) (bool, error) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+
+	if now.Sub(s.lastPrune) >= s.ttl {
+		prune(s.seen, now)
+		s.lastPrune = now
+	}
+
+	if expiresAt, found := s.seen[updateId]; found && now.Before(expiresAt) {
+		return false, nil
+	}
+
+	s.seen[updateId] = now.Add(s.ttl)
+
+	return true, nil
+}
+
+// FileStore keeps the update ids in a JSON file, so they survive restarts during local runs.
+type FileStore struct {
+	mu   sync.Mutex
+	path string
+	ttl  time.Duration
+	now  func() time.Time
+}
+
+func NewFileStore(path string, ttl time.Duration) *FileStore {
+	return &FileStore{
+		path: path,
+		ttl:  ttl,
+		now:  time.Now,
+	}
+}
+
+func (s *FileStore) MarkSeen(updateId int) (bool, error) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seen, err := s.load()
+
+	if err != nil {
+		return false, err
+	}
+
+	now := s.now()
+
+	prune(seen, now)
+
+	if _, found := seen[updateId]; found {
+		return false, nil
+	}
+
+	seen[updateId] = now.Add(s.ttl)
+
+	return true, s.save(seen)
+}
+
+func (s *FileStore) load() (map[int]time.Time, error) {
+
+	var stored map[string]time.Time
+
+	if err := jsonfile.Load(s.path, &stored); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[int]time.Time, len(stored))
+
+	for key, expiresAt := range stored {
+
+		updateId, err := strconv.Atoi(key)
+
+		if err != nil {
+			return nil, err
+		}
+
+		seen[updateId] = expiresAt
+	}
+
+	return seen, nil
+}
+
+func (s *FileStore) save(seen map[int]time.Time) error {
+
+	stored := make(map[string]time.Time, len(seen))
+
+	for updateId, expiresAt := range seen {
+		stored[strconv.Itoa(updateId)] = expiresAt
+	}
+
+	return jsonfile.Save(s.path, stored)
+}
+
+func prune(seen map[int]time.Time, now time.Time) {
+
+	for updateId, expiresAt := range seen {
+		if !now.Before(expiresAt) {
+			delete(seen, updateId)
+		}
+	}
+}
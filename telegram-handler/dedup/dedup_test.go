@@ -0,0 +1,118 @@
+package dedup
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore(t *testing.T) {
+
+	t.Run("Updates are seen once until they expire", func(t *testing.T) {
+
+		// Arrange
+		now := time.Unix(1614894279, 0)
+
+		store := NewMemoryStore(time.Hour)
+
+		store.now = func() time.Time { return now }
+
+		// Act & Assert
+		firstTime, err := store.MarkSeen(1)
+
+		assert.Nil(t, err)
+		assert.True(t, firstTime)
+
+		firstTime, _ = store.MarkSeen(1)
+
+		assert.False(t, firstTime)
+
+		firstTime, _ = store.MarkSeen(2)
+
+		assert.True(t, firstTime)
+
+		now = now.Add(time.Hour)
+
+		firstTime, _ = store.MarkSeen(1)
+
+		assert.True(t, firstTime)
+
+		assert.Equal(t, 1, len(store.seen))
+	})
+}
+
+func TestFileStore(t *testing.T) {
+
+	t.Run("Updates are remembered across instances", func(t *testing.T) {
+
+		// Arrange
+		directory, err := ioutil.TempDir("", "dedup")
+
+		if err != nil {
+			t.Fatal("Can't run test scenario")
+		}
+
+		defer os.RemoveAll(directory)
+
+		path := filepath.Join(directory, "updates.json")
+
+		now := time.Unix(1614894279, 0)
+
+		store := NewFileStore(path, time.Hour)
+
+		store.now = func() time.Time { return now }
+
+		// Act & Assert
+		firstTime, err := store.MarkSeen(1)
+
+		assert.Nil(t, err)
+		assert.True(t, firstTime)
+
+		reopened := NewFileStore(path, time.Hour)
+
+		reopened.now = func() time.Time { return now }
+
+		firstTime, err = reopened.MarkSeen(1)
+
+		assert.Nil(t, err)
+		assert.False(t, firstTime)
+
+		now = now.Add(time.Hour)
+
+		firstTime, err = reopened.MarkSeen(1)
+
+		assert.Nil(t, err)
+		assert.True(t, firstTime)
+
+		files, _ := ioutil.ReadDir(directory)
+
+		assert.Equal(t, 1, len(files))
+	})
+
+	t.Run("Corrupted file", func(t *testing.T) {
+
+		// Arrange
+		file, err := ioutil.TempFile("", "updates.*.json")
+
+		if err != nil {
+			t.Fatal("Can't run test scenario")
+		}
+
+		defer os.Remove(file.Name())
+
+		file.WriteString("batata")
+		file.Close()
+
+		store := NewFileStore(file.Name(), time.Hour)
+
+		// Act
+		_, err = store.MarkSeen(1)
+
+		// Assert
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,503 @@
+package dto
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type GeneratedFact struct {
+	ID        string `json:"id"`
+	Text      string `json:"text"`
+	Source    string `json:"source"`
+	SourceURL string `json:"source_url"`
+	Language  string `json:"language"`
+	Permalink string `json:"permalink"`
+}
+
+// The kinds of facts.
+const (
+	FactKindRandom = "random"
+	FactKindToday  = "today"
+)
+
+// FactQuery narrows down the fact to fetch. Empty fields take the defaults of the client.
+type FactQuery struct {
+	// Kind is either FactKindRandom or FactKindToday, the fact of the day.
+	Kind     string
+	Language string
+}
+
+// JokeQuery narrows down the joke to fetch. Empty fields take the defaults of the joke source.
+type JokeQuery struct {
+	// ID fetches that joke of the source again, leaving out the category and safe mode.
+	ID       string
+	Category string
+	Language string
+	// SafeMode leaves out the jokes the source flags as explicit.
+	SafeMode bool
+}
+
+// The types of jokes.
+const (
+	JokeTypeSingle  = "single"
+	JokeTypeTwoPart = "twopart"
+)
+
+// JokeValue is a joke from any of the joke sources. Single jokes have their
+// text in Joke, two part jokes have a Setup and a Delivery.
+type JokeValue struct {
+	ID         string
+	Joke       string
+	Setup      string
+	Delivery   string
+	Categories []string
+}
+
+// IsTwoPart tells whether the joke has a setup and a delivery rather than a single text.
+func (j *JokeValue) IsTwoPart() bool {
+	return len(j.Setup) > 0 && len(j.Delivery) > 0
+}
+
+// Text is the whole joke, with the delivery of two part jokes after their setup.
+func (j *JokeValue) Text() string {
+
+	if j.IsTwoPart() {
+		return j.Setup + "\n\n" + j.Delivery
+	}
+
+	return j.Joke
+}
+
+// GeneratedJoke is a joke decoded by a joke source, whatever the format of its API.
+type GeneratedJoke struct {
+	// Type is either JokeTypeSingle or JokeTypeTwoPart.
+	Type string
+	// Source is the name of the joke source the joke comes from.
+	Source string
+	Value  JokeValue
+}
+
+// Update is a Telegram object that the handler receives every time an user interacts with the bot.
+// At most one of the optional fields is present in any given update.
+type Update struct {
+	UpdateId          int            `json:"update_id"`
+	Message           Message        `json:"message"`
+	EditedMessage     *Message       `json:"edited_message,omitempty"`
+	ChannelPost       *Message       `json:"channel_post,omitempty"`
+	EditedChannelPost *Message       `json:"edited_channel_post,omitempty"`
+	CallbackQuery     *CallbackQuery `json:"callback_query,omitempty"`
+	InlineQuery       *InlineQuery   `json:"inline_query,omitempty"`
+}
+
+// CommandMessage returns the new message or channel post of the update, the only
+// kinds of message bot commands are handled from. Edits are ignored so a command
+// isn't answered twice.
+func (u *Update) CommandMessage() *Message {
+
+	if !u.Message.isEmpty() {
+		return &u.Message
+	}
+
+	return u.ChannelPost
+}
+
+// EffectiveMessage returns the message the update refers to, whatever its kind.
+func (u *Update) EffectiveMessage() *Message {
+
+	switch {
+	case !u.Message.isEmpty():
+		return &u.Message
+	case u.EditedMessage != nil:
+		return u.EditedMessage
+	case u.ChannelPost != nil:
+		return u.ChannelPost
+	case u.EditedChannelPost != nil:
+		return u.EditedChannelPost
+	case u.CallbackQuery != nil:
+		return u.CallbackQuery.Message
+	}
+
+	return nil
+}
+
+// EffectiveChat returns the chat the update belongs to, nil for inline queries.
+func (u *Update) EffectiveChat() *Chat {
+
+	if message := u.EffectiveMessage(); message != nil {
+		return &message.Chat
+	}
+
+	return nil
+}
+
+// EffectiveUser returns the user that triggered the update. Channel posts have no sender user.
+func (u *Update) EffectiveUser() *User {
+
+	switch {
+	case u.CallbackQuery != nil:
+		return &u.CallbackQuery.From
+	case u.InlineQuery != nil:
+		return &u.InlineQuery.From
+	}
+
+	if message := u.EffectiveMessage(); message != nil {
+		return message.From
+	}
+
+	return nil
+}
+
+// Message is a Telegram object that can be found in an update.
+type Message struct {
+	MessageId       int             `json:"message_id,omitempty"`
+	From            *User           `json:"from,omitempty"`
+	SenderChat      *Chat           `json:"sender_chat,omitempty"`
+	Date            int64           `json:"date,omitempty"`
+	EditDate        int64           `json:"edit_date,omitempty"`
+	Chat            Chat            `json:"chat"`
+	ReplyToMessage  *Message        `json:"reply_to_message,omitempty"`
+	Text            string          `json:"text"`
+	Entities        []MessageEntity `json:"entities,omitempty"`
+	Caption         string          `json:"caption,omitempty"`
+	CaptionEntities []MessageEntity `json:"caption_entities,omitempty"`
+	Photo           []PhotoSize     `json:"photo,omitempty"`
+	Animation       *Animation      `json:"animation,omitempty"`
+	Audio           *Audio          `json:"audio,omitempty"`
+	Document        *Document       `json:"document,omitempty"`
+	Sticker         *Sticker        `json:"sticker,omitempty"`
+	Video           *Video          `json:"video,omitempty"`
+	Voice           *Voice          `json:"voice,omitempty"`
+	Location        *Location       `json:"location,omitempty"`
+}
+
+// Content returns the text of the message, or the caption when the message carries media.
+func (m *Message) Content() string {
+
+	if len(m.Text) == 0 {
+		return m.Caption
+	}
+
+	return m.Text
+}
+
+// ContentEntities returns the entities of the content of the message, see Content.
+func (m *Message) ContentEntities() []MessageEntity {
+
+	if len(m.Text) == 0 {
+		return m.CaptionEntities
+	}
+
+	return m.Entities
+}
+
+func (m *Message) isEmpty() bool {
+	return m.MessageId == 0 && m.Chat.Id == 0 && len(m.Text) == 0 && len(m.Caption) == 0
+}
+
+const (
+	ChatTypePrivate    = "private"
+	ChatTypeGroup      = "group"
+	ChatTypeSupergroup = "supergroup"
+	ChatTypeChannel    = "channel"
+)
+
+// A Telegram Chat indicates the conversation to which the message belongs.
+type Chat struct {
+	Id        int    `json:"id"`
+	Type      string `json:"type,omitempty"`
+	Title     string `json:"title,omitempty"`
+	Username  string `json:"username,omitempty"`
+	FirstName string `json:"first_name,omitempty"`
+	LastName  string `json:"last_name,omitempty"`
+}
+
+// IsGroup tells whether the chat is a group or a supergroup, where other bots may be listening too.
+func (c *Chat) IsGroup() bool {
+	return c.Type == ChatTypeGroup || c.Type == ChatTypeSupergroup
+}
+
+// User is a Telegram user or bot.
+type User struct {
+	Id           int    `json:"id"`
+	IsBot        bool   `json:"is_bot"`
+	FirstName    string `json:"first_name"`
+	LastName     string `json:"last_name,omitempty"`
+	Username     string `json:"username,omitempty"`
+	LanguageCode string `json:"language_code,omitempty"`
+}
+
+// String identifies the user in logs.
+func (u *User) String() string {
+
+	if len(u.Username) > 0 {
+		return fmt.Sprintf("@%s (%d)", u.Username, u.Id)
+	}
+
+	return fmt.Sprintf("%s (%d)", strings.TrimSpace(u.FirstName+" "+u.LastName), u.Id)
+}
+
+const EntityTypeBotCommand = "bot_command"
+
+// MessageEntity is a special entity in a text message, like a bot command, an url or a mention.
+// Offset and Length are measured in UTF-16 code units.
+type MessageEntity struct {
+	Type     string `json:"type"`
+	Offset   int    `json:"offset"`
+	Length   int    `json:"length"`
+	Url      string `json:"url,omitempty"`
+	User     *User  `json:"user,omitempty"`
+	Language string `json:"language,omitempty"`
+}
+
+// CallbackQuery is sent when a user presses a button of an inline keyboard.
+type CallbackQuery struct {
+	Id              string   `json:"id"`
+	From            User     `json:"from"`
+	Message         *Message `json:"message,omitempty"`
+	InlineMessageId string   `json:"inline_message_id,omitempty"`
+	ChatInstance    string   `json:"chat_instance"`
+	Data            string   `json:"data,omitempty"`
+}
+
+// InlineQuery is sent when a user types @BotName in any chat.
+type InlineQuery struct {
+	Id       string    `json:"id"`
+	From     User      `json:"from"`
+	Query    string    `json:"query"`
+	Offset   string    `json:"offset"`
+	ChatType string    `json:"chat_type,omitempty"`
+	Location *Location `json:"location,omitempty"`
+}
+
+type PhotoSize struct {
+	FileId       string `json:"file_id"`
+	FileUniqueId string `json:"file_unique_id"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	FileSize     int    `json:"file_size,omitempty"`
+}
+
+type Animation struct {
+	FileId       string `json:"file_id"`
+	FileUniqueId string `json:"file_unique_id"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	Duration     int    `json:"duration"`
+	FileName     string `json:"file_name,omitempty"`
+	MimeType     string `json:"mime_type,omitempty"`
+	FileSize     int    `json:"file_size,omitempty"`
+}
+
+type Audio struct {
+	FileId       string `json:"file_id"`
+	FileUniqueId string `json:"file_unique_id"`
+	Duration     int    `json:"duration"`
+	Performer    string `json:"performer,omitempty"`
+	Title        string `json:"title,omitempty"`
+	FileName     string `json:"file_name,omitempty"`
+	MimeType     string `json:"mime_type,omitempty"`
+	FileSize     int    `json:"file_size,omitempty"`
+}
+
+type Document struct {
+	FileId       string `json:"file_id"`
+	FileUniqueId string `json:"file_unique_id"`
+	FileName     string `json:"file_name,omitempty"`
+	MimeType     string `json:"mime_type,omitempty"`
+	FileSize     int    `json:"file_size,omitempty"`
+}
+
+type Sticker struct {
+	FileId       string `json:"file_id"`
+	FileUniqueId string `json:"file_unique_id"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	IsAnimated   bool   `json:"is_animated"`
+	Emoji        string `json:"emoji,omitempty"`
+	SetName      string `json:"set_name,omitempty"`
+	FileSize     int    `json:"file_size,omitempty"`
+}
+
+type Video struct {
+	FileId       string `json:"file_id"`
+	FileUniqueId string `json:"file_unique_id"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	Duration     int    `json:"duration"`
+	FileName     string `json:"file_name,omitempty"`
+	MimeType     string `json:"mime_type,omitempty"`
+	FileSize     int    `json:"file_size,omitempty"`
+}
+
+type Voice struct {
+	FileId       string `json:"file_id"`
+	FileUniqueId string `json:"file_unique_id"`
+	Duration     int    `json:"duration"`
+	MimeType     string `json:"mime_type,omitempty"`
+	FileSize     int    `json:"file_size,omitempty"`
+}
+
+type Location struct {
+	Longitude float64 `json:"longitude"`
+	Latitude  float64 `json:"latitude"`
+}
+
+const (
+	ParseModeMarkdownV2 = "MarkdownV2"
+	ParseModeHTML       = "HTML"
+)
+
+// SendMessageRequest holds the parameters of the Telegram sendMessage method.
+type SendMessageRequest struct {
+	ChatId                int    `json:"chat_id"`
+	Text                  string `json:"text"`
+	ParseMode             string `json:"parse_mode,omitempty"`
+	ReplyToMessageId      int    `json:"reply_to_message_id,omitempty"`
+	DisableWebPagePreview bool   `json:"disable_web_page_preview,omitempty"`
+	DisableNotification   bool   `json:"disable_notification,omitempty"`
+	// ReplyMarkup is one of InlineKeyboardMarkup, ReplyKeyboardMarkup, ReplyKeyboardRemove or ForceReply.
+	ReplyMarkup interface{} `json:"reply_markup,omitempty"`
+}
+
+// GetUpdatesRequest holds the parameters of the Telegram getUpdates method.
+type GetUpdatesRequest struct {
+	Offset int `json:"offset,omitempty"`
+	Limit  int `json:"limit,omitempty"`
+	// Timeout is how many seconds Telegram holds the request while there are no updates.
+	Timeout        int      `json:"timeout,omitempty"`
+	AllowedUpdates []string `json:"allowed_updates,omitempty"`
+}
+
+// MethodSendMessage names the sendMessage method in webhook responses.
+const MethodSendMessage = "sendMessage"
+
+// WebhookSendMessage is a sendMessage call made in the response to a webhook request.
+type WebhookSendMessage struct {
+	Method string `json:"method"`
+	*SendMessageRequest
+}
+
+// NewWebhookSendMessage wraps the message so it can be returned as a webhook response.
+func NewWebhookSendMessage(message *SendMessageRequest) *WebhookSendMessage {
+	return &WebhookSendMessage{
+		Method:             MethodSendMessage,
+		SendMessageRequest: message,
+	}
+}
+
+// AnswerCallbackQueryRequest holds the parameters of the Telegram answerCallbackQuery method.
+// Text, when set, is shown to the user who pressed the button.
+type AnswerCallbackQueryRequest struct {
+	CallbackQueryId string `json:"callback_query_id"`
+	Text            string `json:"text,omitempty"`
+	ShowAlert       bool   `json:"show_alert,omitempty"`
+}
+
+// InlineKeyboardMarkup is a keyboard shown right below the message it belongs to.
+type InlineKeyboardMarkup struct {
+	InlineKeyboard [][]InlineKeyboardButton `json:"inline_keyboard"`
+}
+
+// MaxCallbackDataLength is the most bytes Telegram takes in the callback data of a button.
+const MaxCallbackDataLength = 64
+
+type InlineKeyboardButton struct {
+	Text         string `json:"text"`
+	Url          string `json:"url,omitempty"`
+	CallbackData string `json:"callback_data,omitempty"`
+}
+
+// ReplyKeyboardMarkup replaces the user keyboard with custom buttons.
+type ReplyKeyboardMarkup struct {
+	Keyboard        [][]KeyboardButton `json:"keyboard"`
+	ResizeKeyboard  bool               `json:"resize_keyboard,omitempty"`
+	OneTimeKeyboard bool               `json:"one_time_keyboard,omitempty"`
+	Selective       bool               `json:"selective,omitempty"`
+}
+
+type KeyboardButton struct {
+	Text string `json:"text"`
+}
+
+// ReplyKeyboardRemove removes a custom keyboard previously sent by the bot.
+type ReplyKeyboardRemove struct {
+	RemoveKeyboard bool `json:"remove_keyboard"`
+	Selective      bool `json:"selective,omitempty"`
+}
+
+// ForceReply makes the user's client show a reply interface to the bot's message.
+type ForceReply struct {
+	ForceReply bool `json:"force_reply"`
+	Selective  bool `json:"selective,omitempty"`
+}
+
+// Response is the envelope every Telegram Bot API method answers with.
+type Response struct {
+	Ok          bool                `json:"ok"`
+	Result      json.RawMessage     `json:"result,omitempty"`
+	ErrorCode   int                 `json:"error_code,omitempty"`
+	Description string              `json:"description,omitempty"`
+	Parameters  *ResponseParameters `json:"parameters,omitempty"`
+}
+
+// ResponseParameters explain why a request failed and how it can be retried.
+type ResponseParameters struct {
+	MigrateToChatId int `json:"migrate_to_chat_id,omitempty"`
+	RetryAfter      int `json:"retry_after,omitempty"`
+}
+
+// SetWebhookRequest holds the parameters of the Telegram setWebhook method.
+type SetWebhookRequest struct {
+	Url string `json:"url"`
+	// SecretToken is sent back by Telegram in the X-Telegram-Bot-Api-Secret-Token header of every webhook request.
+	SecretToken        string   `json:"secret_token,omitempty"`
+	AllowedUpdates     []string `json:"allowed_updates,omitempty"`
+	MaxConnections     int      `json:"max_connections,omitempty"`
+	DropPendingUpdates bool     `json:"drop_pending_updates,omitempty"`
+}
+
+// DeleteWebhookRequest holds the parameters of the Telegram deleteWebhook method.
+type DeleteWebhookRequest struct {
+	DropPendingUpdates bool `json:"drop_pending_updates,omitempty"`
+}
+
+// WebhookInfo describes the current webhook of a bot. Url is empty when no webhook is set.
+type WebhookInfo struct {
+	Url                          string   `json:"url"`
+	HasCustomCertificate         bool     `json:"has_custom_certificate"`
+	PendingUpdateCount           int      `json:"pending_update_count"`
+	IpAddress                    string   `json:"ip_address,omitempty"`
+	LastErrorDate                int      `json:"last_error_date,omitempty"`
+	LastErrorMessage             string   `json:"last_error_message,omitempty"`
+	LastSynchronizationErrorDate int      `json:"last_synchronization_error_date,omitempty"`
+	MaxConnections               int      `json:"max_connections,omitempty"`
+	AllowedUpdates               []string `json:"allowed_updates,omitempty"`
+}
+
+// The types of scope a command menu can be set for that don't depend on a chat.
+const (
+	BotCommandScopeDefault               = "default"
+	BotCommandScopeAllPrivateChats       = "all_private_chats"
+	BotCommandScopeAllGroupChats         = "all_group_chats"
+	BotCommandScopeAllChatAdministrators = "all_chat_administrators"
+)
+
+// BotCommand is an entry of the command menu Telegram shows to the users.
+type BotCommand struct {
+	Command     string `json:"command"`
+	Description string `json:"description"`
+}
+
+// BotCommandScope tells which users see a command menu.
+type BotCommandScope struct {
+	Type string `json:"type"`
+}
+
+// SetMyCommandsRequest holds the parameters of the Telegram setMyCommands method.
+type SetMyCommandsRequest struct {
+	Commands []BotCommand     `json:"commands"`
+	Scope    *BotCommandScope `json:"scope,omitempty"`
+	// LanguageCode is the two letter ISO 639-1 code of the users seeing the menu, or empty for everyone else.
+	LanguageCode string `json:"language_code,omitempty"`
+}
@@ -0,0 +1,132 @@
+package dto
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeMessageUpdate(t *testing.T) {
+
+	t.Run("Decode message update", func(t *testing.T) {
+
+		rawUpdate := "{\"update_id\": 10000,\"message\": {\"message_id\": 1365,\"from\": {\"id\": 690639026,\"is_bot\": false,\"first_name\": \"Mário\",\"username\": \"mario\",\"language_code\": \"pt\"},\"chat\": {\"id\": -255361673,\"title\": \"Pokémons\",\"type\": \"group\"},\"date\": 1614894279,\"reply_to_message\": {\"message_id\": 1364,\"chat\": {\"id\": -255361673,\"type\": \"group\"},\"date\": 1614894270,\"text\": \"hello\"},\"photo\": [{\"file_id\": \"AgAD\",\"file_unique_id\": \"AQAD\",\"width\": 90,\"height\": 51}],\"caption\": \"/fact@MyDailyFactBot\",\"caption_entities\": [{\"type\": \"bot_command\",\"offset\": 0,\"length\": 20}]}}"
+
+		var update Update
+
+		// Act
+		err := json.Unmarshal([]byte(rawUpdate), &update)
+
+		// Assert
+
+		assert.Nil(t, err)
+
+		assert.Equal(t, 10000, update.UpdateId)
+
+		assert.Equal(t, &update.Message, update.CommandMessage())
+
+		assert.Equal(t, &update.Message, update.EffectiveMessage())
+
+		assert.Equal(t, "group", update.EffectiveChat().Type)
+
+		assert.Equal(t, "Pokémons", update.EffectiveChat().Title)
+
+		assert.Equal(t, "@mario (690639026)", update.EffectiveUser().String())
+
+		assert.Equal(t, "/fact@MyDailyFactBot", update.Message.Content())
+
+		assert.Equal(t, "bot_command", update.Message.CaptionEntities[0].Type)
+
+		assert.Equal(t, 1364, update.Message.ReplyToMessage.MessageId)
+
+		assert.Equal(t, 90, update.Message.Photo[0].Width)
+	})
+}
+
+func TestDecodeOtherUpdates(t *testing.T) {
+
+	t.Run("Decode edited message", func(t *testing.T) {
+
+		rawUpdate := "{\"update_id\": 10001,\"edited_message\": {\"message_id\": 1365,\"from\": {\"id\": 690639026,\"is_bot\": false,\"first_name\": \"Mário\"},\"chat\": {\"id\": 690639026,\"type\": \"private\"},\"date\": 1614894279,\"edit_date\": 1614894290,\"text\": \"/joke\"}}"
+
+		var update Update
+
+		// Act
+		err := json.Unmarshal([]byte(rawUpdate), &update)
+
+		// Assert
+
+		assert.Nil(t, err)
+
+		assert.Nil(t, update.CommandMessage())
+
+		assert.Equal(t, update.EditedMessage, update.EffectiveMessage())
+
+		assert.Equal(t, "Mário (690639026)", update.EffectiveUser().String())
+	})
+
+	t.Run("Decode channel post", func(t *testing.T) {
+
+		rawUpdate := "{\"update_id\": 10002,\"channel_post\": {\"message_id\": 7,\"sender_chat\": {\"id\": -1001,\"title\": \"Facts\",\"type\": \"channel\"},\"chat\": {\"id\": -1001,\"title\": \"Facts\",\"type\": \"channel\"},\"date\": 1614894279,\"text\": \"/fact\",\"entities\": [{\"type\": \"bot_command\",\"offset\": 0,\"length\": 5}]}}"
+
+		var update Update
+
+		// Act
+		err := json.Unmarshal([]byte(rawUpdate), &update)
+
+		// Assert
+
+		assert.Nil(t, err)
+
+		assert.Equal(t, update.ChannelPost, update.CommandMessage())
+
+		assert.Equal(t, -1001, update.EffectiveChat().Id)
+
+		assert.Nil(t, update.EffectiveUser())
+	})
+
+	t.Run("Decode callback query", func(t *testing.T) {
+
+		rawUpdate := "{\"update_id\": 10003,\"callback_query\": {\"id\": \"4382bfdwdsb323b2d9\",\"from\": {\"id\": 690639026,\"is_bot\": false,\"first_name\": \"Mário\",\"last_name\": \"Silva\"},\"message\": {\"message_id\": 1366,\"chat\": {\"id\": 690639026,\"type\": \"private\"},\"date\": 1614894279,\"text\": \"Why?\"},\"chat_instance\": \"-1234\",\"data\": \"punchline\"}}"
+
+		var update Update
+
+		// Act
+		err := json.Unmarshal([]byte(rawUpdate), &update)
+
+		// Assert
+
+		assert.Nil(t, err)
+
+		assert.Nil(t, update.CommandMessage())
+
+		assert.Equal(t, "punchline", update.CallbackQuery.Data)
+
+		assert.Equal(t, 690639026, update.EffectiveChat().Id)
+
+		assert.Equal(t, "Mário Silva (690639026)", update.EffectiveUser().String())
+	})
+
+	t.Run("Decode inline query", func(t *testing.T) {
+
+		rawUpdate := "{\"update_id\": 10004,\"inline_query\": {\"id\": \"1\",\"from\": {\"id\": 690639026,\"is_bot\": false,\"first_name\": \"Mário\"},\"query\": \"joke\",\"offset\": \"\",\"chat_type\": \"private\"}}"
+
+		var update Update
+
+		// Act
+		err := json.Unmarshal([]byte(rawUpdate), &update)
+
+		// Assert
+
+		assert.Nil(t, err)
+
+		assert.Nil(t, update.EffectiveMessage())
+
+		assert.Nil(t, update.EffectiveChat())
+
+		assert.Equal(t, "joke", update.InlineQuery.Query)
+
+		assert.Equal(t, 690639026, update.EffectiveUser().Id)
+	})
+}
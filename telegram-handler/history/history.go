@@ -0,0 +1,98 @@
+package history
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Store remembers the items, like facts and jokes, delivered to each chat, so
+// the same content isn't sent to a chat twice in a row.
+type Store interface {
+	// Seen tells whether the item was delivered to the chat and not forgotten since.
+	Seen(ctx context.Context, chatId int, itemId string) (bool, error)
+	// Add remembers that the item was delivered to the chat.
+	Add(ctx context.Context, chatId int, itemId string) error
+}
+
+// MemoryStore keeps the last items of every chat in memory, up to the given size
+// per chat, for the given time to live.
+type MemoryStore struct {
+	mu    sync.Mutex
+	size  int
+	ttl   time.Duration
+	chats map[int][]item
+	now   func() time.Time
+}
+
+type item struct {
+	id        string
+	expiresAt time.Time
+}
+
+func NewMemoryStore(size int, ttl time.Duration) *MemoryStore {
+	return &MemoryStore{
+		size:  size,
+		ttl:   ttl,
+		chats: map[int][]item{},
+		now:   time.Now,
+	}
+}
+
+func (s *MemoryStore) Seen(ctx context.Context, chatId int, itemId string) (bool, error) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, delivered := range s.prune(chatId) {
+		if delivered.id == itemId {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (s *MemoryStore) Add(ctx context.Context, chatId int, itemId string) error {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	items := []item{}
+
+	for _, delivered := range s.prune(chatId) {
+		if delivered.id != itemId {
+			items = append(items, delivered)
+		}
+	}
+
+	items = append(items, item{id: itemId, expiresAt: s.now().Add(s.ttl)})
+
+	if len(items) > s.size {
+		items = items[len(items)-s.size:]
+	}
+
+	s.chats[chatId] = items
+
+	return nil
+}
+
+// prune forgets the expired items of the chat, returning the others from the oldest to the newest.
+func (s *MemoryStore) prune(chatId int) []item {
+
+	now := s.now()
+	items := s.chats[chatId]
+
+	for len(items) > 0 && !now.Before(items[0].expiresAt) {
+		items = items[1:]
+	}
+
+	if len(items) == 0 {
+		delete(s.chats, chatId)
+		return nil
+	}
+
+	s.chats[chatId] = items
+
+	return items
+}
@@ -0,0 +1,72 @@
+package history
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore(t *testing.T) {
+
+	t.Run("Items are remembered per chat until they expire", func(t *testing.T) {
+
+		// Arrange
+		now := time.Unix(1614894279, 0)
+
+		store := NewMemoryStore(10, time.Hour)
+
+		store.now = func() time.Time { return now }
+
+		// Act & Assert
+		seen, err := store.Seen(context.Background(), 1234, "fact:1")
+
+		assert.Nil(t, err)
+		assert.False(t, seen)
+
+		assert.Nil(t, store.Add(context.Background(), 1234, "fact:1"))
+
+		seen, _ = store.Seen(context.Background(), 1234, "fact:1")
+
+		assert.True(t, seen)
+
+		seen, _ = store.Seen(context.Background(), 5678, "fact:1")
+
+		assert.False(t, seen)
+
+		now = now.Add(time.Hour)
+
+		seen, _ = store.Seen(context.Background(), 1234, "fact:1")
+
+		assert.False(t, seen)
+
+		assert.Equal(t, 0, len(store.chats))
+	})
+
+	t.Run("Only the last items of a chat are remembered", func(t *testing.T) {
+
+		// Arrange
+		store := NewMemoryStore(2, time.Hour)
+
+		// Act
+		store.Add(context.Background(), 1234, "fact:1")
+		store.Add(context.Background(), 1234, "fact:2")
+		store.Add(context.Background(), 1234, "fact:1")
+		store.Add(context.Background(), 1234, "fact:3")
+
+		// Assert
+
+		seenFirst, _ := store.Seen(context.Background(), 1234, "fact:1")
+
+		assert.True(t, seenFirst)
+
+		seenSecond, _ := store.Seen(context.Background(), 1234, "fact:2")
+
+		assert.False(t, seenSecond)
+
+		seenThird, _ := store.Seen(context.Background(), 1234, "fact:3")
+
+		assert.True(t, seenThird)
+	})
+}
@@ -0,0 +1,57 @@
+// Package jsonfile keeps a value in a JSON file, for the stores that remember their
+// state across restarts during local runs. The files are meant for a single process
+// at a time.
+package jsonfile
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Load decodes the file into the value, leaving the value as it is when the file doesn't exist yet.
+func Load(path string, value interface{}) error {
+
+	content, err := ioutil.ReadFile(path)
+
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(content, value)
+}
+
+// Save encodes the value into the file. It writes to a temporary file first, so a
+// crash never leaves a truncated file behind.
+func Save(path string, value interface{}) error {
+
+	content, err := json.Marshal(value)
+
+	if err != nil {
+		return err
+	}
+
+	temporary, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*")
+
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(temporary.Name())
+
+	if _, err := temporary.Write(content); err != nil {
+		temporary.Close()
+		return err
+	}
+
+	if err := temporary.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(temporary.Name(), path)
+}
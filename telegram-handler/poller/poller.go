@@ -0,0 +1,149 @@
+package poller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"my-first-telegram-bot/telegram-handler/bot"
+	"my-first-telegram-bot/telegram-handler/dto"
+	"my-first-telegram-bot/telegram-handler/restclient"
+	"net/http"
+	"time"
+)
+
+var (
+	// DefaultTimeout is how long Telegram holds each getUpdates request while there are no updates.
+	DefaultTimeout = 30 * time.Second
+
+	// DefaultUpdateTimeout bounds the processing of each update, like the Lambda timeout does.
+	DefaultUpdateTimeout = 5 * time.Second
+
+	// ErrorBackoff is how long the poller waits before polling again after a failed poll.
+	ErrorBackoff = 3 * time.Second
+
+	// requestTimeoutMargin leaves time for the getUpdates response to arrive once Telegram stops holding it.
+	requestTimeoutMargin = 10 * time.Second
+)
+
+// UpdateProcessor handles the updates the poller fetches.
+type UpdateProcessor interface {
+	ProcessUpdate(ctx context.Context, body string) *bot.Response
+}
+
+// Poller fetches the updates of the bot with getUpdates and processes them one
+// at a time, in order. It is meant for local development, where there is no
+// public address for Telegram to deliver a webhook to.
+type Poller struct {
+	Timeout        time.Duration
+	UpdateTimeout  time.Duration
+	AllowedUpdates []string
+
+	client    restclient.UpdatesClient
+	processor UpdateProcessor
+	clock     restclient.Clock
+	offset    int
+}
+
+func NewPoller(client restclient.UpdatesClient, processor UpdateProcessor, clock restclient.Clock) *Poller {
+	return &Poller{
+		Timeout:       DefaultTimeout,
+		UpdateTimeout: DefaultUpdateTimeout,
+		client:        client,
+		processor:     processor,
+		clock:         clock,
+	}
+}
+
+// Offset is the id of the first update the next poll asks for.
+func (p *Poller) Offset() int {
+	return p.offset
+}
+
+// Run polls for updates until the context is done, backing off after failed polls.
+func (p *Poller) Run(ctx context.Context) error {
+
+	for {
+
+		_, err := p.Poll(ctx)
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if err == nil {
+			continue
+		}
+
+		backoff := errorBackoff(err)
+
+		log.Printf("Polling for updates failed, polling again in %v: %v", backoff, err)
+
+		if err := p.clock.Sleep(ctx, backoff); err != nil {
+			return err
+		}
+	}
+}
+
+// Poll fetches the pending updates once and processes them, returning how many
+// were processed. The offset moves past each update as soon as it is processed,
+// so no update is fetched again once handled.
+func (p *Poller) Poll(ctx context.Context) (int, error) {
+
+	pollCtx, cancel := context.WithTimeout(ctx, p.Timeout+requestTimeoutMargin)
+
+	updates, err := p.client.GetUpdatesWithContext(pollCtx, &dto.GetUpdatesRequest{
+		Offset:         p.offset,
+		Timeout:        int(p.Timeout / time.Second),
+		AllowedUpdates: p.AllowedUpdates,
+	})
+
+	cancel()
+
+	if err != nil {
+		return 0, err
+	}
+
+	for processed, update := range updates {
+
+		if ctx.Err() != nil {
+			return processed, ctx.Err()
+		}
+
+		body, err := json.Marshal(update)
+
+		if err != nil {
+			return processed, err
+		}
+
+		updateCtx, cancel := context.WithTimeout(ctx, p.UpdateTimeout)
+
+		p.processor.ProcessUpdate(updateCtx, string(body))
+
+		cancel()
+
+		p.offset = update.UpdateId + 1
+	}
+
+	return len(updates), nil
+}
+
+// errorBackoff waits as long as Telegram asks to, when it does.
+func errorBackoff(err error) time.Duration {
+
+	var apiError *restclient.TelegramAPIError
+
+	if !errors.As(err, &apiError) {
+		return ErrorBackoff
+	}
+
+	if apiError.Code == http.StatusConflict {
+		log.Printf("Telegram doesn't allow getUpdates while a webhook is set, delete the webhook to poll for updates")
+	}
+
+	if retryAfter := apiError.RetryAfter(); retryAfter > 0 {
+		return retryAfter
+	}
+
+	return ErrorBackoff
+}
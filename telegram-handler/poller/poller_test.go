@@ -0,0 +1,176 @@
+package poller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"my-first-telegram-bot/telegram-handler/bot"
+	"my-first-telegram-bot/telegram-handler/dto"
+	"my-first-telegram-bot/telegram-handler/restclient"
+	"my-first-telegram-bot/telegram-handler/utils/mocks"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeTelegram serves getUpdates from a queue of responses, recording the requests it got.
+type fakeTelegram struct {
+	mu        sync.Mutex
+	responses []string
+	requests  []dto.GetUpdatesRequest
+}
+
+func (fake *fakeTelegram) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	var request dto.GetUpdatesRequest
+
+	json.NewDecoder(r.Body).Decode(&request)
+
+	fake.requests = append(fake.requests, request)
+
+	response := "{\"ok\": true,\"result\": []}"
+
+	if len(fake.responses) > 0 {
+		response, fake.responses = fake.responses[0], fake.responses[1:]
+	}
+
+	w.Write([]byte(response))
+}
+
+type recordingProcessor struct {
+	bodies []string
+}
+
+func (processor *recordingProcessor) ProcessUpdate(ctx context.Context, body string) *bot.Response {
+
+	processor.bodies = append(processor.bodies, body)
+
+	return &bot.Response{StatusCode: 200}
+}
+
+func updatesResponse(updateIds ...int) string {
+
+	updates := make([]dto.Update, 0, len(updateIds))
+
+	for _, updateId := range updateIds {
+		updates = append(updates, dto.Update{
+			UpdateId: updateId,
+			Message: dto.Message{
+				Text: fmt.Sprintf("/fact %d", updateId),
+				Chat: dto.Chat{Id: 1234, Type: "private"},
+			},
+		})
+	}
+
+	result, _ := json.Marshal(updates)
+
+	return fmt.Sprintf("{\"ok\": true,\"result\": %s}", result)
+}
+
+func TestPoll(t *testing.T) {
+
+	t.Run("Updates are processed in order and the offset moves past them", func(t *testing.T) {
+
+		// Arrange
+		fake := &fakeTelegram{responses: []string{updatesResponse(10, 11), updatesResponse(12)}}
+
+		server := httptest.NewServer(fake)
+		defer server.Close()
+
+		processor := &recordingProcessor{}
+
+		updatesPoller := NewPoller(restclient.NewBaseClient(server.Client(), server.URL+"/bottoken/getUpdates"), processor, &mocks.MockClock{})
+
+		// Act
+		firstCount, firstErr := updatesPoller.Poll(context.Background())
+
+		secondCount, secondErr := updatesPoller.Poll(context.Background())
+
+		// Assert
+
+		assert.Nil(t, firstErr)
+
+		assert.Nil(t, secondErr)
+
+		assert.Equal(t, 2, firstCount)
+
+		assert.Equal(t, 1, secondCount)
+
+		assert.Equal(t, 13, updatesPoller.Offset())
+
+		assert.Equal(t, 0, fake.requests[0].Offset)
+
+		assert.Equal(t, 12, fake.requests[1].Offset)
+
+		assert.Equal(t, 30, fake.requests[0].Timeout)
+
+		assert.Equal(t, 3, len(processor.bodies))
+
+		for i, updateId := range []int{10, 11, 12} {
+
+			var update dto.Update
+
+			json.Unmarshal([]byte(processor.bodies[i]), &update)
+
+			assert.Equal(t, updateId, update.UpdateId)
+		}
+	})
+}
+
+func TestRun(t *testing.T) {
+
+	t.Run("Failed polls are retried after the backoff Telegram asks for", func(t *testing.T) {
+
+		// Arrange
+		fake := &fakeTelegram{responses: []string{
+			"{\"ok\": false,\"error_code\": 429,\"description\": \"Too Many Requests: retry after 7\",\"parameters\": {\"retry_after\": 7}}",
+			"{\"ok\": false,\"error_code\": 500,\"description\": \"Internal Server Error\"}",
+			updatesResponse(10),
+		}}
+
+		server := httptest.NewServer(fake)
+		defer server.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+
+		processor := &cancellingProcessor{cancel: cancel}
+
+		clock := &mocks.MockClock{}
+
+		updatesPoller := NewPoller(restclient.NewBaseClient(server.Client(), server.URL+"/bottoken/getUpdates"), processor, clock)
+
+		// Act
+		err := updatesPoller.Run(ctx)
+
+		// Assert
+
+		assert.Equal(t, context.Canceled, err)
+
+		assert.Equal(t, []time.Duration{7 * time.Second, ErrorBackoff}, clock.Slept)
+
+		assert.Equal(t, 1, processor.count)
+
+		assert.Equal(t, 11, updatesPoller.Offset())
+	})
+}
+
+// cancellingProcessor stops the poller once it processed an update.
+type cancellingProcessor struct {
+	count  int
+	cancel context.CancelFunc
+}
+
+func (processor *cancellingProcessor) ProcessUpdate(ctx context.Context, body string) *bot.Response {
+
+	processor.count++
+
+	processor.cancel()
+
+	return &bot.Response{StatusCode: 200}
+}
@@ -0,0 +1,105 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"my-first-telegram-bot/telegram-handler/commands"
+	"my-first-telegram-bot/telegram-handler/dto"
+	"my-first-telegram-bot/telegram-handler/restclient"
+	"my-first-telegram-bot/telegram-handler/settings"
+	"strings"
+)
+
+// FactProvider serves random useless facts, or the fact of the day, in any of the
+// languages of the facts API. The language of the chat is used unless one is asked for.
+// Facts are identified by their id, so the bot can avoid repeating them.
+type FactProvider struct {
+	client restclient.FactClient
+}
+
+func NewFactProvider(client restclient.FactClient) *FactProvider {
+	return &FactProvider{client: client}
+}
+
+func (p *FactProvider) Name() string {
+	return "fact"
+}
+
+func (p *FactProvider) Parameters() []commands.Parameter {
+	return []commands.Parameter{
+		{Name: "kind", Values: restclient.FactKinds},
+		{Name: "language", Values: restclient.FactLanguages},
+	}
+}
+
+func (p *FactProvider) Fetch(ctx context.Context, args string) (*commands.Reply, error) {
+
+	arguments, err := commands.ParseArguments(p.Parameters(), args)
+
+	if err != nil {
+		return nil, err
+	}
+
+	language := arguments["language"]
+
+	if language == "" {
+		language = settings.FromContext(ctx).Language
+	}
+
+	kind := arguments["kind"]
+
+	// The fact of the day doesn't change, so a chat that already got it gets a random one.
+	if kind == "" && commands.IsRefetch(ctx) {
+		kind = dto.FactKindRandom
+	}
+
+	generatedFact, err := p.client.GetFactWithQuery(ctx, &dto.FactQuery{
+		Kind:     kind,
+		Language: language,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	reply := &commands.Reply{
+		Text:                  renderFact(generatedFact),
+		ParseMode:             dto.ParseModeHTML,
+		DisableWebPagePreview: true,
+	}
+
+	// Asking for the fact of the day again is expected to repeat it.
+	if kind != dto.FactKindToday {
+		reply.ItemId = generatedFact.ID
+	}
+
+	return reply, nil
+}
+
+// renderFact formats a fact as HTML, linking its source and permalink when they are known.
+func renderFact(fact *dto.GeneratedFact) string {
+
+	var links []string
+
+	if len(fact.SourceURL) > 0 {
+
+		source := fact.Source
+
+		if len(source) == 0 {
+			source = "Source"
+		}
+
+		links = append(links, fmt.Sprintf("<a href=\"%s\">%s</a>", html.EscapeString(fact.SourceURL), html.EscapeString(source)))
+	}
+
+	if len(fact.Permalink) > 0 {
+		links = append(links, fmt.Sprintf("<a href=\"%s\">Permalink</a>", html.EscapeString(fact.Permalink)))
+	}
+
+	if len(links) == 0 {
+		return html.EscapeString(fact.Text)
+	}
+
+	return html.EscapeString(fact.Text) + "\n\n" + strings.Join(links, " · ")
+}
@@ -0,0 +1,160 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"my-first-telegram-bot/telegram-handler/commands"
+	"my-first-telegram-bot/telegram-handler/dto"
+	"my-first-telegram-bot/telegram-handler/settings"
+	"my-first-telegram-bot/telegram-handler/utils/mocks"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFactProvider(t *testing.T) {
+
+	t.Run("Facts are rendered as HTML", func(t *testing.T) {
+
+		// Arrange
+		client := &mocks.MockBaseClient{}
+
+		client.GetFactFunc = func() (*dto.GeneratedFact, error) {
+			return &dto.GeneratedFact{
+				Text:      "Cats <3 boxes",
+				Source:    "djtech.net",
+				SourceURL: "http://www.djtech.net/humor/useless_facts.htm",
+			}, nil
+		}
+
+		provider := NewFactProvider(client)
+
+		// Act
+		reply, err := provider.Fetch(context.Background(), "")
+
+		// Assert
+		assert.Nil(t, err)
+
+		assert.Equal(t, "fact", provider.Name())
+
+		assert.Equal(t, dto.ParseModeHTML, reply.ParseMode)
+
+		assert.Equal(t, "Cats &lt;3 boxes\n\n<a href=\"http://www.djtech.net/humor/useless_facts.htm\">djtech.net</a>", reply.Text)
+	})
+
+	t.Run("Failed fact", func(t *testing.T) {
+
+		// Arrange
+		client := &mocks.MockBaseClient{}
+
+		client.GetFactFunc = func() (*dto.GeneratedFact, error) {
+			return &dto.GeneratedFact{}, errors.New("batata")
+		}
+
+		provider := NewFactProvider(client)
+
+		// Act
+		reply, err := provider.Fetch(context.Background(), "")
+
+		// Assert
+		assert.NotNil(t, err)
+
+		assert.Nil(t, reply)
+	})
+
+	t.Run("Arguments pick the kind and language of the fact", func(t *testing.T) {
+
+		// Arrange
+		client := &mocks.MockBaseClient{}
+
+		client.GetFactFunc = func() (*dto.GeneratedFact, error) {
+			return &dto.GeneratedFact{Text: "Kartoffeln"}, nil
+		}
+
+		provider := NewFactProvider(client)
+
+		// Act
+		_, err := provider.Fetch(context.Background(), "DE random")
+
+		// Assert
+		assert.Nil(t, err)
+
+		assert.Equal(t, &dto.FactQuery{Kind: dto.FactKindRandom, Language: "de"}, client.LastFactQuery)
+	})
+
+	t.Run("Facts are in the language of the chat unless asked otherwise", func(t *testing.T) {
+
+		// Arrange
+		client := &mocks.MockBaseClient{}
+
+		client.GetFactFunc = func() (*dto.GeneratedFact, error) {
+			return &dto.GeneratedFact{Text: "Kartoffeln"}, nil
+		}
+
+		provider := NewFactProvider(client)
+
+		ctx := settings.NewContext(context.Background(), &settings.ChatSettings{Language: "de"})
+
+		// Act
+		_, err := provider.Fetch(ctx, "")
+
+		chatLanguageQuery := client.LastFactQuery
+
+		_, askedErr := provider.Fetch(ctx, "en")
+
+		// Assert
+		assert.Nil(t, err)
+
+		assert.Equal(t, &dto.FactQuery{Language: "de"}, chatLanguageQuery)
+
+		assert.Nil(t, askedErr)
+
+		assert.Equal(t, &dto.FactQuery{Language: "en"}, client.LastFactQuery)
+	})
+
+	t.Run("Facts are identified unless the fact of the day is asked for", func(t *testing.T) {
+
+		// Arrange
+		client := &mocks.MockBaseClient{}
+
+		client.GetFactFunc = func() (*dto.GeneratedFact, error) {
+			return &dto.GeneratedFact{ID: "f1d2", Text: "Bananas are berries."}, nil
+		}
+
+		provider := NewFactProvider(client)
+
+		// Act
+		reply, err := provider.Fetch(context.Background(), "")
+
+		todayReply, todayErr := provider.Fetch(context.Background(), "today")
+
+		// Assert
+		assert.Nil(t, err)
+
+		assert.Equal(t, "f1d2", reply.ItemId)
+
+		assert.Nil(t, todayErr)
+
+		assert.Equal(t, "", todayReply.ItemId)
+	})
+
+	t.Run("Refetches look for a random fact", func(t *testing.T) {
+
+		// Arrange
+		client := &mocks.MockBaseClient{}
+
+		client.GetFactFunc = func() (*dto.GeneratedFact, error) {
+			return &dto.GeneratedFact{ID: "f1d2", Text: "Bananas are berries."}, nil
+		}
+
+		provider := NewFactProvider(client)
+
+		// Act
+		_, err := provider.Fetch(commands.NewRefetchContext(context.Background()), "")
+
+		// Assert
+		assert.Nil(t, err)
+
+		assert.Equal(t, &dto.FactQuery{Kind: dto.FactKindRandom}, client.LastFactQuery)
+	})
+}
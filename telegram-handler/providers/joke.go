@@ -0,0 +1,135 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"my-first-telegram-bot/telegram-handler/commands"
+	"my-first-telegram-bot/telegram-handler/dto"
+	"my-first-telegram-bot/telegram-handler/restclient"
+	"my-first-telegram-bot/telegram-handler/settings"
+	"strings"
+)
+
+var ErrPunchlineGone = errors.New("The joke source no longer has the punchline")
+
+// JokeProvider serves random jokes from the configured joke source. Two part jokes
+// are answered with their setup, followed up by their punchline. Jokes come from the
+// preferred categories of the chat unless one is asked for, and explicit ones are
+// left out unless the chat allows them.
+type JokeProvider struct {
+	client restclient.JokeClient
+}
+
+func NewJokeProvider(client restclient.JokeClient) *JokeProvider {
+	return &JokeProvider{client: client}
+}
+
+func (p *JokeProvider) Name() string {
+	return "joke"
+}
+
+// Parameters are the categories of the joke source, if it has any.
+func (p *JokeProvider) Parameters() []commands.Parameter {
+	return []commands.Parameter{
+		{Name: "category", Values: p.client.Categories()},
+	}
+}
+
+func (p *JokeProvider) Fetch(ctx context.Context, args string) (*commands.Reply, error) {
+
+	arguments, err := commands.ParseArguments(p.Parameters(), args)
+
+	if err != nil {
+		return nil, err
+	}
+
+	chatSettings := settings.FromContext(ctx)
+
+	category := arguments["category"]
+
+	if category == "" {
+		category = p.preferredCategory(chatSettings.JokeCategories)
+	}
+
+	generatedJoke, err := p.client.GetJokeWithQuery(ctx, &dto.JokeQuery{
+		Category: category,
+		Language: chatSettings.Language,
+		SafeMode: !chatSettings.AllowExplicit,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	var reply *commands.Reply
+
+	if generatedJoke.Value.IsTwoPart() {
+		reply = commands.TextReply(generatedJoke.Value.Setup)
+		reply.FollowUp = commands.TextReply(generatedJoke.Value.Delivery)
+	} else {
+		reply = commands.TextReply(generatedJoke.Value.Text())
+	}
+
+	reply.ItemId = jokeItemId(generatedJoke)
+
+	return reply, nil
+}
+
+// FetchFollowUp fetches the joke with the item id again, answering with its punchline,
+// in the language of the chat.
+func (p *JokeProvider) FetchFollowUp(ctx context.Context, itemId string) (*commands.Reply, error) {
+
+	parts := strings.SplitN(itemId, ":", 2)
+
+	if len(parts) != 2 {
+		return nil, ErrPunchlineGone
+	}
+
+	generatedJoke, err := p.client.GetJokeWithQuery(ctx, &dto.JokeQuery{
+		ID:       parts[1],
+		Language: settings.FromContext(ctx).Language,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	// The configured source may have changed since the joke was sent.
+	if jokeItemId(generatedJoke) != itemId || !generatedJoke.Value.IsTwoPart() {
+		return nil, ErrPunchlineGone
+	}
+
+	return commands.TextReply(generatedJoke.Value.Delivery), nil
+}
+
+// preferredCategory picks one of the preferred categories the joke source has, or
+// none when it has none of them.
+func (p *JokeProvider) preferredCategory(preferred []string) string {
+
+	var available []string
+
+	for _, category := range preferred {
+		for _, known := range p.client.Categories() {
+			if category == known {
+				available = append(available, category)
+			}
+		}
+	}
+
+	if len(available) == 0 {
+		return ""
+	}
+
+	return available[rand.Intn(len(available))]
+}
+
+// jokeItemId identifies the joke by its source too, since every source numbers its jokes.
+func jokeItemId(joke *dto.GeneratedJoke) string {
+
+	if joke.Value.ID == "" {
+		return ""
+	}
+
+	return joke.Source + ":" + joke.Value.ID
+}
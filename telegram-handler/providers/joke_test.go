@@ -0,0 +1,173 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"my-first-telegram-bot/telegram-handler/commands"
+	"my-first-telegram-bot/telegram-handler/dto"
+	"my-first-telegram-bot/telegram-handler/settings"
+	"my-first-telegram-bot/telegram-handler/utils/mocks"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJokeProvider(t *testing.T) {
+
+	t.Run("Jokes are plain text", func(t *testing.T) {
+
+		// Arrange
+		client := &mocks.MockBaseClient{}
+
+		client.GetJokeFunc = func() (*dto.GeneratedJoke, error) {
+			return &dto.GeneratedJoke{Value: dto.JokeValue{ID: "1", Joke: "Chuck Norris can divide by zero."}}, nil
+		}
+
+		provider := NewJokeProvider(client)
+
+		// Act
+		reply, err := provider.Fetch(context.Background(), "")
+
+		// Assert
+		assert.Nil(t, err)
+
+		assert.Equal(t, "joke", provider.Name())
+
+		assert.Equal(t, "Chuck Norris can divide by zero.", reply.Text)
+
+		assert.Equal(t, "", reply.ParseMode)
+
+		assert.Equal(t, ":1", reply.ItemId)
+	})
+
+	t.Run("Two part jokes follow up with their punchline", func(t *testing.T) {
+
+		// Arrange
+		client := &mocks.MockBaseClient{}
+
+		client.GetJokeFunc = func() (*dto.GeneratedJoke, error) {
+			return &dto.GeneratedJoke{
+				Type:   dto.JokeTypeTwoPart,
+				Source: "jokeapi",
+				Value: dto.JokeValue{
+					ID:       "232",
+					Setup:    "Why do programmers prefer dark mode?",
+					Delivery: "Because light attracts bugs.",
+				},
+			}, nil
+		}
+
+		provider := NewJokeProvider(client)
+
+		// Act
+		reply, err := provider.Fetch(context.Background(), "")
+
+		// Assert
+		assert.Nil(t, err)
+
+		assert.Equal(t, "Why do programmers prefer dark mode?", reply.Text)
+
+		assert.Equal(t, commands.TextReply("Because light attracts bugs."), reply.FollowUp)
+
+		assert.Equal(t, "jokeapi:232", reply.ItemId)
+	})
+
+	t.Run("Punchlines are fetched again by the id of their joke", func(t *testing.T) {
+
+		scenarios := []struct {
+			name              string
+			itemId            string
+			expectedPunchline *commands.Reply
+			expectedError     error
+		}{
+			{name: "Joke of the source", itemId: "jokeapi:232", expectedPunchline: commands.TextReply("Because light attracts bugs.")},
+			{name: "Joke of another source", itemId: "officialjokeapi:232", expectedError: ErrPunchlineGone},
+			{name: "Joke without an id", itemId: "232", expectedError: ErrPunchlineGone},
+		}
+
+		for _, scenario := range scenarios {
+
+			// Arrange
+			client := &mocks.MockBaseClient{}
+
+			client.GetJokeFunc = func() (*dto.GeneratedJoke, error) {
+				return &dto.GeneratedJoke{
+					Type:   dto.JokeTypeTwoPart,
+					Source: "jokeapi",
+					Value: dto.JokeValue{
+						ID:       "232",
+						Setup:    "Why do programmers prefer dark mode?",
+						Delivery: "Because light attracts bugs.",
+					},
+				}, nil
+			}
+
+			provider := NewJokeProvider(client)
+
+			ctx := settings.NewContext(context.Background(), &settings.ChatSettings{Language: "de"})
+
+			// Act
+			punchline, err := provider.FetchFollowUp(ctx, scenario.itemId)
+
+			// Assert
+
+			assert.Equal(t, scenario.expectedPunchline, punchline, scenario.name)
+
+			assert.True(t, errors.Is(err, scenario.expectedError), scenario.name)
+
+			if scenario.expectedError == nil {
+				assert.Equal(t, &dto.JokeQuery{ID: "232", Language: "de"}, client.LastJokeQuery)
+			}
+		}
+	})
+
+	t.Run("Arguments pick the category of the joke", func(t *testing.T) {
+
+		// Arrange
+		client := &mocks.MockBaseClient{JokeCategories: []string{"programming", "pun"}}
+
+		client.GetJokeFunc = func() (*dto.GeneratedJoke, error) {
+			return &dto.GeneratedJoke{Value: dto.JokeValue{ID: "1", Joke: "I'm reading a book about anti-gravity. It's impossible to put down."}}, nil
+		}
+
+		provider := NewJokeProvider(client)
+
+		// Act
+		_, err := provider.Fetch(context.Background(), "pun")
+
+		_, badErr := provider.Fetch(context.Background(), "dark")
+
+		// Assert
+		assert.Nil(t, err)
+
+		assert.Equal(t, &dto.JokeQuery{Category: "pun", SafeMode: true}, client.LastJokeQuery)
+
+		assert.True(t, errors.Is(badErr, commands.ErrInvalidArguments))
+	})
+
+	t.Run("Jokes follow the settings of the chat", func(t *testing.T) {
+
+		// Arrange
+		client := &mocks.MockBaseClient{JokeCategories: []string{"programming", "pun"}}
+
+		client.GetJokeFunc = func() (*dto.GeneratedJoke, error) {
+			return &dto.GeneratedJoke{Value: dto.JokeValue{ID: "1", Joke: "Ich habe einen Witz über Zeitreisen, aber ihr mochtet ihn nicht."}}, nil
+		}
+
+		provider := NewJokeProvider(client)
+
+		ctx := settings.NewContext(context.Background(), &settings.ChatSettings{
+			Language:       "de",
+			JokeCategories: []string{"dark", "pun"},
+			AllowExplicit:  true,
+		})
+
+		// Act
+		_, err := provider.Fetch(ctx, "")
+
+		// Assert
+		assert.Nil(t, err)
+
+		assert.Equal(t, &dto.JokeQuery{Category: "pun", Language: "de"}, client.LastJokeQuery)
+	})
+}
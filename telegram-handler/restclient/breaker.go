@@ -0,0 +1,187 @@
+package restclient
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+var ErrCircuitOpen = errors.New("Circuit breaker is open")
+
+type CircuitState int
+
+const (
+	CircuitClosed CircuitState = iota
+	CircuitOpen
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+
+	return "unknown"
+}
+
+// CircuitBreakerSettings are the thresholds driving the state changes of a CircuitBreaker.
+type CircuitBreakerSettings struct {
+	// FailureThreshold is how many consecutive failures open the circuit.
+	FailureThreshold int
+	// OpenTimeout is how long the circuit stays open before letting probe requests through.
+	OpenTimeout time.Duration
+	// HalfOpenSuccesses is how many probe requests have to succeed to close the circuit again,
+	// it is also the number of probes allowed at the same time.
+	HalfOpenSuccesses int
+}
+
+var DefaultCircuitBreakerSettings = CircuitBreakerSettings{
+	FailureThreshold:  5,
+	OpenTimeout:       30 * time.Second,
+	HalfOpenSuccesses: 1,
+}
+
+// CircuitBreaker stops calling an upstream that keeps failing, so callers fail fast
+// instead of waiting on the network. Every state change is logged.
+type CircuitBreaker struct {
+	mu        sync.Mutex
+	name      string
+	settings  CircuitBreakerSettings
+	clock     Clock
+	state     CircuitState
+	failures  int
+	successes int
+	probes    int
+	openedAt  time.Time
+}
+
+func NewCircuitBreaker(name string, settings CircuitBreakerSettings, clock Clock) *CircuitBreaker {
+	return &CircuitBreaker{
+		name:     name,
+		settings: settings,
+		clock:    clock,
+		state:    CircuitClosed,
+	}
+}
+
+// State returns the current state, moving an open circuit to half-open once its timeout is over.
+func (cb *CircuitBreaker) State() CircuitState {
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.checkOpenTimeout()
+
+	return cb.state
+}
+
+// Allow tells whether a request may go through, returning ErrCircuitOpen when it may not.
+// Every allowed request has to be followed by a call to Record.
+func (cb *CircuitBreaker) Allow() error {
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.checkOpenTimeout()
+
+	switch cb.state {
+	case CircuitOpen:
+		return ErrCircuitOpen
+	case CircuitHalfOpen:
+		if cb.probes >= cb.halfOpenSuccesses() {
+			return ErrCircuitOpen
+		}
+
+		cb.probes++
+	}
+
+	return nil
+}
+
+// Record reports the outcome of an allowed request. Cancelled requests say
+// nothing about the upstream health and leave the state untouched.
+func (cb *CircuitBreaker) Record(err error) {
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state == CircuitHalfOpen && cb.probes > 0 {
+		cb.probes--
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+
+	if err == nil {
+		cb.onSuccess()
+	} else {
+		cb.onFailure()
+	}
+}
+
+func (cb *CircuitBreaker) onSuccess() {
+
+	switch cb.state {
+	case CircuitClosed:
+		cb.failures = 0
+	case CircuitHalfOpen:
+		cb.successes++
+
+		if cb.successes >= cb.halfOpenSuccesses() {
+			cb.setState(CircuitClosed)
+		}
+	}
+}
+
+func (cb *CircuitBreaker) onFailure() {
+
+	switch cb.state {
+	case CircuitClosed:
+		cb.failures++
+
+		if cb.failures >= cb.settings.FailureThreshold {
+			cb.setState(CircuitOpen)
+		}
+	case CircuitHalfOpen:
+		cb.setState(CircuitOpen)
+	}
+}
+
+func (cb *CircuitBreaker) checkOpenTimeout() {
+
+	if cb.state == CircuitOpen && cb.clock.Now().Sub(cb.openedAt) >= cb.settings.OpenTimeout {
+		cb.setState(CircuitHalfOpen)
+	}
+}
+
+func (cb *CircuitBreaker) setState(state CircuitState) {
+
+	log.Printf("Circuit breaker %s went from %s to %s", cb.name, cb.state, state)
+
+	cb.state = state
+	cb.failures = 0
+	cb.successes = 0
+	cb.probes = 0
+
+	if state == CircuitOpen {
+		cb.openedAt = cb.clock.Now()
+	}
+}
+
+func (cb *CircuitBreaker) halfOpenSuccesses() int {
+
+	if cb.settings.HalfOpenSuccesses < 1 {
+		return 1
+	}
+
+	return cb.settings.HalfOpenSuccesses
+}
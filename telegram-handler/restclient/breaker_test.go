@@ -0,0 +1,162 @@
+package restclient
+
+import (
+	"context"
+	"errors"
+	"my-first-telegram-bot/telegram-handler/utils/mocks"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testBreakerSettings = CircuitBreakerSettings{
+	FailureThreshold:  3,
+	OpenTimeout:       30 * time.Second,
+	HalfOpenSuccesses: 2,
+}
+
+func TestCircuitBreakerStates(t *testing.T) {
+
+	failure := errors.New("batata")
+
+	t.Run("Opens after consecutive failures", func(t *testing.T) {
+
+		// Arrange
+		breaker := NewCircuitBreaker("test", testBreakerSettings, &mocks.MockClock{})
+
+		// Act
+		for i := 0; i < 2; i++ {
+			assert.Nil(t, breaker.Allow())
+			breaker.Record(failure)
+		}
+
+		assert.Nil(t, breaker.Allow())
+		breaker.Record(nil)
+
+		for i := 0; i < 3; i++ {
+			assert.Nil(t, breaker.Allow())
+			breaker.Record(failure)
+		}
+
+		// Assert
+		assert.Equal(t, CircuitOpen, breaker.State())
+
+		assert.Equal(t, ErrCircuitOpen, breaker.Allow())
+	})
+
+	t.Run("Half-open closes after successful probes", func(t *testing.T) {
+
+		// Arrange
+		clock := &mocks.MockClock{}
+
+		breaker := NewCircuitBreaker("test", testBreakerSettings, clock)
+
+		for i := 0; i < 3; i++ {
+			breaker.Allow()
+			breaker.Record(failure)
+		}
+
+		// Act
+		clock.Advance(29 * time.Second)
+
+		assert.Equal(t, CircuitOpen, breaker.State())
+
+		clock.Advance(time.Second)
+
+		assert.Equal(t, CircuitHalfOpen, breaker.State())
+
+		assert.Nil(t, breaker.Allow())
+		assert.Nil(t, breaker.Allow())
+		assert.Equal(t, ErrCircuitOpen, breaker.Allow())
+
+		breaker.Record(nil)
+
+		assert.Equal(t, CircuitHalfOpen, breaker.State())
+
+		breaker.Record(nil)
+
+		// Assert
+		assert.Equal(t, CircuitClosed, breaker.State())
+	})
+
+	t.Run("Half-open reopens on failure", func(t *testing.T) {
+
+		// Arrange
+		clock := &mocks.MockClock{}
+
+		breaker := NewCircuitBreaker("test", testBreakerSettings, clock)
+
+		for i := 0; i < 3; i++ {
+			breaker.Allow()
+			breaker.Record(failure)
+		}
+
+		clock.Advance(30 * time.Second)
+
+		// Act
+		assert.Nil(t, breaker.Allow())
+
+		breaker.Record(failure)
+
+		// Assert
+		assert.Equal(t, CircuitOpen, breaker.State())
+
+		clock.Advance(29 * time.Second)
+
+		assert.Equal(t, ErrCircuitOpen, breaker.Allow())
+	})
+
+	t.Run("Cancelled requests are neutral", func(t *testing.T) {
+
+		// Arrange
+		breaker := NewCircuitBreaker("test", testBreakerSettings, &mocks.MockClock{})
+
+		// Act
+		for i := 0; i < 5; i++ {
+			breaker.Allow()
+			breaker.Record(context.Canceled)
+		}
+
+		// Assert
+		assert.Equal(t, CircuitClosed, breaker.State())
+	})
+}
+
+func TestCircuitBreakerFailsFast(t *testing.T) {
+
+	t.Run("Open circuit skips the upstream", func(t *testing.T) {
+
+		// Arrange
+		clock := &mocks.MockClock{}
+
+		calls := 0
+
+		factClient := NewBaseClient(
+			&mocks.MockHttpClient{
+				DoFunc: func(*http.Request) (*http.Response, error) {
+					calls++
+					return statusResponse(502, "")()
+				},
+			},
+			"temp").
+			WithClock(clock).
+			WithCircuitBreaker(NewCircuitBreaker("facts", testBreakerSettings, clock))
+
+		for i := 0; i < 3; i++ {
+			_, err := factClient.GetFact()
+
+			assert.Equal(t, ErrNon200Response, err)
+		}
+
+		// Act
+		_, err := factClient.GetFact()
+
+		// Assert
+
+		assert.Equal(t, ErrCircuitOpen, err)
+
+		assert.Equal(t, 3, calls)
+	})
+}
@@ -0,0 +1,43 @@
+package restclient
+
+import (
+	"context"
+	"time"
+)
+
+// Clock abstracts time so throttling and backoff can be tested without actually waiting.
+type Clock interface {
+	Now() time.Time
+	// Sleep waits for the duration, or returns the context error if it is done first.
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) Sleep(ctx context.Context, d time.Duration) error {
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// SystemClock is the Clock backed by the real time.
+var SystemClock Clock = systemClock{}
+
+// hasTimeFor tells whether the context deadline, if any, leaves at least d of time.
+func hasTimeFor(ctx context.Context, d time.Duration) bool {
+
+	deadline, ok := ctx.Deadline()
+
+	return !ok || time.Until(deadline) >= d
+}
@@ -0,0 +1,393 @@
+package restclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"my-first-telegram-bot/telegram-handler/dto"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultJokeSource is the joke source used unless configured otherwise.
+	DefaultJokeSource = "jokeapi"
+
+	// JokeCategoryPlaceholder stands for the category in the address of a joke source.
+	JokeCategoryPlaceholder = "{category}"
+
+	// JokeIdPlaceholder stands for the id of a joke in the address of a joke source.
+	JokeIdPlaceholder = "{id}"
+)
+
+var (
+	ErrUnknownJokeSource   = errors.New("Unknown joke source")
+	ErrUnknownJokeCategory = errors.New("Unknown joke category")
+	ErrEmptyJoke           = errors.New("The joke source answered without a joke")
+	ErrNoJokeAddress       = errors.New("The joke source can't fetch jokes by id")
+)
+
+// JokeSource is a joke API along with how to decode its responses.
+type JokeSource struct {
+	Name string
+	// Address is where jokes are fetched from, with JokeCategoryPlaceholder
+	// standing for the category when the source has categories.
+	Address string
+	// JokeAddress is where a joke is fetched from again, with JokeIdPlaceholder
+	// standing for its id. Empty when the source can't fetch jokes by id.
+	JokeAddress string
+	// Categories are the categories the source advertises, the first one being the default.
+	Categories []string
+	// Languages are the languages the source has jokes in, the first one being the default.
+	// Jokes in other languages are fetched in the default one.
+	Languages []string
+	// LanguageParameter is the query parameter picking the language of the jokes.
+	LanguageParameter string
+	// SafeModeParameter is the query parameter leaving explicit jokes out. Sources
+	// without it are expected to only have safe jokes in their categories.
+	SafeModeParameter string
+	// Headers are sent along with every request, some APIs need them to answer with JSON.
+	Headers map[string]string
+	Decode  func(body []byte) (*dto.GeneratedJoke, error)
+}
+
+// JokeSources are the joke sources that can be configured, by name. Register
+// more with RegisterJokeSource.
+var JokeSources = map[string]*JokeSource{}
+
+// RegisterJokeSource makes the joke source available by its name.
+func RegisterJokeSource(source *JokeSource) {
+	JokeSources[source.Name] = source
+}
+
+// JokeSourceNames lists the names of the registered joke sources.
+func JokeSourceNames() []string {
+
+	names := make([]string, 0, len(JokeSources))
+
+	for name := range JokeSources {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func init() {
+
+	RegisterJokeSource(&JokeSource{
+		Name:              "jokeapi",
+		Address:           "https://v2.jokeapi.dev/joke/" + JokeCategoryPlaceholder,
+		JokeAddress:       "https://v2.jokeapi.dev/joke/Any?idRange=" + JokeIdPlaceholder,
+		Categories:        []string{"programming", "misc", "pun", "spooky", "christmas"},
+		Languages:         []string{"en", "de", "cs", "es", "fr", "pt"},
+		LanguageParameter: "lang",
+		SafeModeParameter: "safe-mode",
+		Decode:            decodeJokeApiJoke,
+	})
+
+	RegisterJokeSource(&JokeSource{
+		Name:        "chucknorris",
+		Address:     "https://api.chucknorris.io/jokes/random?category=" + JokeCategoryPlaceholder,
+		JokeAddress: "https://api.chucknorris.io/jokes/" + JokeIdPlaceholder,
+		Categories: []string{"dev", "animal", "career", "celebrity", "fashion", "food", "history",
+			"money", "movie", "music", "science", "sport", "travel"},
+		Decode: decodeChuckNorrisJoke,
+	})
+
+	RegisterJokeSource(&JokeSource{
+		Name:        "officialjokeapi",
+		Address:     "https://official-joke-api.appspot.com/jokes/" + JokeCategoryPlaceholder + "/random",
+		JokeAddress: "https://official-joke-api.appspot.com/jokes/" + JokeIdPlaceholder,
+		Categories:  []string{"programming", "general", "knock-knock", "dad"},
+		Decode:      decodeOfficialJokeApiJoke,
+	})
+
+	RegisterJokeSource(&JokeSource{
+		Name:        "icanhazdadjoke",
+		Address:     "https://icanhazdadjoke.com/",
+		JokeAddress: "https://icanhazdadjoke.com/j/" + JokeIdPlaceholder,
+		Headers: map[string]string{
+			"Accept":     "application/json",
+			"User-Agent": "my-first-telegram-bot (https://github.com/ei09010/serverless-experiment)",
+		},
+		Decode: decodeICanHazDadJoke,
+	})
+}
+
+// JokeSourceClient fetches jokes from a joke source.
+type JokeSourceClient struct {
+	*BaseClient
+	source *JokeSource
+}
+
+// NewJokeClient creates a client for the joke source with the given name.
+func NewJokeClient(name string) (*JokeSourceClient, error) {
+
+	source, found := JokeSources[name]
+
+	if !found {
+		return nil, fmt.Errorf("%w %q, the known ones are %v", ErrUnknownJokeSource, name, JokeSourceNames())
+	}
+
+	return NewJokeSourceClient(
+		NewBaseClient(&http.Client{Timeout: ContentApiTimeout}, source.Address).
+			WithRetryPolicy(DefaultRetryPolicy).
+			WithCircuitBreaker(NewCircuitBreaker("jokes", DefaultCircuitBreakerSettings, SystemClock)),
+		source), nil
+}
+
+// NewJokeSourceClient fetches jokes from the source through the client, sending the headers of the source.
+func NewJokeSourceClient(client *BaseClient, source *JokeSource) *JokeSourceClient {
+
+	for name, value := range source.Headers {
+		client.WithHeader(name, value)
+	}
+
+	return &JokeSourceClient{
+		BaseClient: client,
+		source:     source,
+	}
+}
+
+func (jc *JokeSourceClient) GetJoke() (*dto.GeneratedJoke, error) {
+
+	return jc.GetJokeWithContext(context.Background())
+}
+
+// GetJokeWithContext fetches a joke, giving up on retries and requests once the context is done.
+func (jc *JokeSourceClient) GetJokeWithContext(ctx context.Context) (*dto.GeneratedJoke, error) {
+
+	return jc.GetJokeWithQuery(ctx, &dto.JokeQuery{})
+}
+
+// GetJokeWithQuery fetches a joke of the category of the query, or the joke with its id.
+func (jc *JokeSourceClient) GetJokeWithQuery(ctx context.Context, query *dto.JokeQuery) (*dto.GeneratedJoke, error) {
+
+	jokeUrl, err := jc.jokeUrl(query)
+
+	if err != nil {
+		return &dto.GeneratedJoke{}, err
+	}
+
+	r, err := get(ctx, jc.BaseClient, jokeUrl)
+
+	if err != nil {
+		return &dto.GeneratedJoke{}, err
+	}
+
+	defer r.Body.Close()
+
+	body, err := ioutil.ReadAll(r.Body)
+
+	if err != nil {
+		return &dto.GeneratedJoke{}, err
+	}
+
+	joke, err := jc.source.Decode(body)
+
+	if err != nil {
+		return &dto.GeneratedJoke{}, err
+	}
+
+	// Telegram refuses to send an empty message, so an empty joke is no joke at all.
+	if strings.TrimSpace(joke.Value.Text()) == "" {
+		return &dto.GeneratedJoke{}, ErrEmptyJoke
+	}
+
+	joke.Source = jc.source.Name
+
+	return joke, nil
+}
+
+// Categories lists the categories the joke source advertises.
+func (jc *JokeSourceClient) Categories() []string {
+	return jc.source.Categories
+}
+
+// jokeUrl fills the category of the query, or the default one, in the address of the
+// client, adding the language and safe mode parameters when the source takes them.
+// Jokes asked for by id are fetched from the joke address of the source instead.
+func (jc *JokeSourceClient) jokeUrl(query *dto.JokeQuery) (string, error) {
+
+	if query.ID != "" {
+		return jc.jokeByIdUrl(query)
+	}
+
+	jokeUrl := jc.url
+
+	if len(jc.source.Categories) == 0 && query.Category != "" {
+		return "", fmt.Errorf("%w %q, %s has no categories", ErrUnknownJokeCategory, query.Category, jc.source.Name)
+	}
+
+	if len(jc.source.Categories) > 0 {
+
+		category := jc.source.Categories[0]
+
+		if query.Category != "" {
+			category = query.Category
+		}
+
+		if !contains(jc.source.Categories, category) {
+			return "", fmt.Errorf("%w %q, the known ones are %v", ErrUnknownJokeCategory, category, jc.source.Categories)
+		}
+
+		jokeUrl = strings.Replace(jokeUrl, JokeCategoryPlaceholder, url.PathEscape(category), 1)
+	}
+
+	jokeUrl = jc.withLanguage(jokeUrl, query.Language)
+
+	if query.SafeMode && jc.source.SafeModeParameter != "" {
+		jokeUrl = withQueryParameter(jokeUrl, jc.source.SafeModeParameter)
+	}
+
+	return jokeUrl, nil
+}
+
+// jokeByIdUrl fills the id of the query in the joke address of the source, adding the
+// language parameter when the source takes it, since some number their jokes by language.
+func (jc *JokeSourceClient) jokeByIdUrl(query *dto.JokeQuery) (string, error) {
+
+	if jc.source.JokeAddress == "" {
+		return "", fmt.Errorf("%w: %s", ErrNoJokeAddress, jc.source.Name)
+	}
+
+	jokeUrl := strings.Replace(jc.source.JokeAddress, JokeIdPlaceholder, url.PathEscape(query.ID), 1)
+
+	return jc.withLanguage(jokeUrl, query.Language), nil
+}
+
+// withLanguage adds the language parameter to the address when the source takes it and
+// has jokes in the language, other than the default one.
+func (jc *JokeSourceClient) withLanguage(jokeUrl string, language string) string {
+
+	if jc.source.LanguageParameter == "" || len(jc.source.Languages) == 0 ||
+		language == jc.source.Languages[0] || !contains(jc.source.Languages, language) {
+
+		return jokeUrl
+	}
+
+	return withQueryParameter(jokeUrl, jc.source.LanguageParameter+"="+url.QueryEscape(language))
+}
+
+func withQueryParameter(address string, parameter string) string {
+
+	if strings.Contains(address, "?") {
+		return address + "&" + parameter
+	}
+
+	return address + "?" + parameter
+}
+
+// newJoke sorts a joke out as single or two part from its parts.
+func newJoke(id string, joke string, setup string, delivery string, categories []string) *dto.GeneratedJoke {
+
+	generatedJoke := &dto.GeneratedJoke{
+		Type: dto.JokeTypeSingle,
+		Value: dto.JokeValue{
+			ID:         id,
+			Joke:       joke,
+			Setup:      setup,
+			Delivery:   delivery,
+			Categories: categories,
+		},
+	}
+
+	if generatedJoke.Value.IsTwoPart() {
+		generatedJoke.Type = dto.JokeTypeTwoPart
+	}
+
+	return generatedJoke
+}
+
+// decodeJokeApiJoke decodes the jokes of https://jokeapi.dev, single or two part.
+func decodeJokeApiJoke(body []byte) (*dto.GeneratedJoke, error) {
+
+	var joke struct {
+		Error    bool   `json:"error"`
+		Message  string `json:"message"`
+		Category string `json:"category"`
+		Joke     string `json:"joke"`
+		Setup    string `json:"setup"`
+		Delivery string `json:"delivery"`
+		ID       int    `json:"id"`
+	}
+
+	if err := json.Unmarshal(body, &joke); err != nil {
+		return nil, err
+	}
+
+	if joke.Error {
+		return nil, fmt.Errorf("JokeAPI error: %s", joke.Message)
+	}
+
+	return newJoke(strconv.Itoa(joke.ID), joke.Joke, joke.Setup, joke.Delivery, []string{joke.Category}), nil
+}
+
+// decodeChuckNorrisJoke decodes the single jokes of https://api.chucknorris.io.
+func decodeChuckNorrisJoke(body []byte) (*dto.GeneratedJoke, error) {
+
+	var joke struct {
+		ID         string   `json:"id"`
+		Value      string   `json:"value"`
+		Categories []string `json:"categories"`
+	}
+
+	if err := json.Unmarshal(body, &joke); err != nil {
+		return nil, err
+	}
+
+	return newJoke(joke.ID, joke.Value, "", "", joke.Categories), nil
+}
+
+// decodeOfficialJokeApiJoke decodes the two part jokes of the Official Joke API,
+// which answers with either a joke or a list of them.
+func decodeOfficialJokeApiJoke(body []byte) (*dto.GeneratedJoke, error) {
+
+	type officialJoke struct {
+		ID        int    `json:"id"`
+		Type      string `json:"type"`
+		Setup     string `json:"setup"`
+		Punchline string `json:"punchline"`
+	}
+
+	var jokes []officialJoke
+
+	if err := json.Unmarshal(body, &jokes); err != nil {
+
+		var joke officialJoke
+
+		if err := json.Unmarshal(body, &joke); err != nil {
+			return nil, err
+		}
+
+		jokes = []officialJoke{joke}
+	}
+
+	if len(jokes) == 0 {
+		return newJoke("", "", "", "", nil), nil
+	}
+
+	return newJoke(strconv.Itoa(jokes[0].ID), "", jokes[0].Setup, jokes[0].Punchline, []string{jokes[0].Type}), nil
+}
+
+// decodeICanHazDadJoke decodes the single jokes of https://icanhazdadjoke.com.
+func decodeICanHazDadJoke(body []byte) (*dto.GeneratedJoke, error) {
+
+	var joke struct {
+		ID   string `json:"id"`
+		Joke string `json:"joke"`
+	}
+
+	if err := json.Unmarshal(body, &joke); err != nil {
+		return nil, err
+	}
+
+	return newJoke(joke.ID, joke.Joke, "", "", nil), nil
+}
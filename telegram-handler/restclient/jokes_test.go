@@ -0,0 +1,305 @@
+package restclient
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"my-first-telegram-bot/telegram-handler/dto"
+	"my-first-telegram-bot/telegram-handler/utils/mocks"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func jokeFixture(t *testing.T, name string) string {
+
+	fixture, err := ioutil.ReadFile(filepath.Join("testdata", "jokes", name))
+
+	if err != nil {
+		t.Fatal("Can't run test scenario")
+	}
+
+	return string(fixture)
+}
+
+func TestJokeSources(t *testing.T) {
+
+	scenarios := []struct {
+		name         string
+		source       string
+		fixture      string
+		expectedJoke *dto.GeneratedJoke
+	}{
+		{
+			name:    "JokeAPI single joke",
+			source:  "jokeapi",
+			fixture: "jokeapi_single.json",
+			expectedJoke: &dto.GeneratedJoke{
+				Type:   dto.JokeTypeSingle,
+				Source: "jokeapi",
+				Value: dto.JokeValue{
+					ID:         "29",
+					Joke:       "There are only 10 kinds of people in this world: those who know binary and those who don't.",
+					Categories: []string{"Programming"},
+				},
+			},
+		},
+		{
+			name:    "JokeAPI two part joke",
+			source:  "jokeapi",
+			fixture: "jokeapi_twopart.json",
+			expectedJoke: &dto.GeneratedJoke{
+				Type:   dto.JokeTypeTwoPart,
+				Source: "jokeapi",
+				Value: dto.JokeValue{
+					ID:         "232",
+					Setup:      "Why do programmers prefer dark mode?",
+					Delivery:   "Because light attracts bugs.",
+					Categories: []string{"Programming"},
+				},
+			},
+		},
+		{
+			name:    "Chuck Norris joke",
+			source:  "chucknorris",
+			fixture: "chucknorris.json",
+			expectedJoke: &dto.GeneratedJoke{
+				Type:   dto.JokeTypeSingle,
+				Source: "chucknorris",
+				Value: dto.JokeValue{
+					ID:         "elgv2wkvt8ioag6xywykbq",
+					Joke:       "Chuck Norris's keyboard doesn't have a Ctrl key because nothing controls Chuck Norris.",
+					Categories: []string{"dev"},
+				},
+			},
+		},
+		{
+			name:    "Official Joke API joke",
+			source:  "officialjokeapi",
+			fixture: "officialjokeapi.json",
+			expectedJoke: &dto.GeneratedJoke{
+				Type:   dto.JokeTypeTwoPart,
+				Source: "officialjokeapi",
+				Value: dto.JokeValue{
+					ID:         "16",
+					Setup:      "How many programmers does it take to change a light bulb?",
+					Delivery:   "None, that's a hardware problem.",
+					Categories: []string{"programming"},
+				},
+			},
+		},
+		{
+			name:    "icanhazdadjoke joke",
+			source:  "icanhazdadjoke",
+			fixture: "icanhazdadjoke.json",
+			expectedJoke: &dto.GeneratedJoke{
+				Type:   dto.JokeTypeSingle,
+				Source: "icanhazdadjoke",
+				Value: dto.JokeValue{
+					ID:   "R7UfaahVfFd",
+					Joke: "My dog used to chase people on a bike a lot. It got so bad I had to take his bike away.",
+				},
+			},
+		},
+	}
+
+	for _, scenario := range scenarios {
+
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+
+			var sentHeaders http.Header
+
+			// Arrange
+			source := JokeSources[scenario.source]
+
+			httpClient := &mocks.MockHttpClient{
+				DoFunc: func(req *http.Request) (*http.Response, error) {
+
+					sentHeaders = req.Header
+
+					return statusResponse(200, jokeFixture(t, scenario.fixture))()
+				},
+			}
+
+			jokeClient := NewJokeSourceClient(NewBaseClient(httpClient, source.Address), source)
+
+			// Act
+			joke, err := jokeClient.GetJoke()
+
+			// Assert
+
+			assert.Nil(t, err)
+
+			assert.Equal(t, scenario.expectedJoke, joke)
+
+			for name, value := range source.Headers {
+				assert.Equal(t, value, sentHeaders.Get(name))
+			}
+		})
+	}
+
+	t.Run("JokeAPI error", func(t *testing.T) {
+
+		// Arrange
+		httpClient := &mocks.MockHttpClient{
+			DoFunc: func(*http.Request) (*http.Response, error) {
+				return statusResponse(200, jokeFixture(t, "jokeapi_error.json"))()
+			},
+		}
+
+		jokeClient := NewJokeSourceClient(NewBaseClient(httpClient, "temp"), JokeSources["jokeapi"])
+
+		// Act
+		_, err := jokeClient.GetJoke()
+
+		// Assert
+
+		assert.EqualError(t, err, "JokeAPI error: No matching joke found")
+	})
+
+	t.Run("Official Joke API without jokes", func(t *testing.T) {
+
+		// Arrange
+		httpClient := &mocks.MockHttpClient{
+			DoFunc: func(*http.Request) (*http.Response, error) {
+				return statusResponse(200, jokeFixture(t, "officialjokeapi_empty.json"))()
+			},
+		}
+
+		jokeClient := NewJokeSourceClient(NewBaseClient(httpClient, "temp"), JokeSources["officialjokeapi"])
+
+		// Act
+		_, err := jokeClient.GetJoke()
+
+		// Assert
+
+		assert.True(t, errors.Is(err, ErrEmptyJoke))
+	})
+
+	t.Run("Unknown joke source", func(t *testing.T) {
+
+		// Act
+		jokeClient, err := NewJokeClient("icndb")
+
+		// Assert
+
+		assert.Nil(t, jokeClient)
+
+		assert.True(t, errors.Is(err, ErrUnknownJokeSource))
+	})
+}
+
+func TestJokeCategories(t *testing.T) {
+
+	scenarios := []struct {
+		name          string
+		source        string
+		query         *dto.JokeQuery
+		expectedUrl   string
+		expectedError error
+	}{
+		{
+			name:        "Default category",
+			source:      "jokeapi",
+			query:       &dto.JokeQuery{},
+			expectedUrl: "https://v2.jokeapi.dev/joke/programming",
+		},
+		{
+			name:        "Category in the path",
+			source:      "officialjokeapi",
+			query:       &dto.JokeQuery{Category: "knock-knock"},
+			expectedUrl: "https://official-joke-api.appspot.com/jokes/knock-knock/random",
+		},
+		{
+			name:        "Category in the query string",
+			source:      "chucknorris",
+			query:       &dto.JokeQuery{Category: "science"},
+			expectedUrl: "https://api.chucknorris.io/jokes/random?category=science",
+		},
+		{
+			name:        "Language and safe mode",
+			source:      "jokeapi",
+			query:       &dto.JokeQuery{Category: "pun", Language: "de", SafeMode: true},
+			expectedUrl: "https://v2.jokeapi.dev/joke/pun?lang=de&safe-mode",
+		},
+		{
+			name:        "Default language",
+			source:      "jokeapi",
+			query:       &dto.JokeQuery{Language: "en"},
+			expectedUrl: "https://v2.jokeapi.dev/joke/programming",
+		},
+		{
+			name:        "Language and safe mode the source doesn't take",
+			source:      "chucknorris",
+			query:       &dto.JokeQuery{Category: "dev", Language: "de", SafeMode: true},
+			expectedUrl: "https://api.chucknorris.io/jokes/random?category=dev",
+		},
+		{
+			name:        "Joke by id",
+			source:      "officialjokeapi",
+			query:       &dto.JokeQuery{ID: "16"},
+			expectedUrl: "https://official-joke-api.appspot.com/jokes/16",
+		},
+		{
+			name:        "Joke by id in a language",
+			source:      "jokeapi",
+			query:       &dto.JokeQuery{ID: "232", Category: "pun", Language: "de", SafeMode: true},
+			expectedUrl: "https://v2.jokeapi.dev/joke/Any?idRange=232&lang=de",
+		},
+		{
+			name:          "Unknown category",
+			source:        "jokeapi",
+			query:         &dto.JokeQuery{Category: "dark"},
+			expectedError: ErrUnknownJokeCategory,
+		},
+		{
+			name:          "Source without categories",
+			source:        "icanhazdadjoke",
+			query:         &dto.JokeQuery{Category: "dad"},
+			expectedError: ErrUnknownJokeCategory,
+		},
+	}
+
+	for _, scenario := range scenarios {
+
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+
+			var requestedUrls []string
+
+			// Arrange
+			source := JokeSources[scenario.source]
+
+			httpClient := &mocks.MockHttpClient{
+				DoFunc: func(req *http.Request) (*http.Response, error) {
+
+					requestedUrls = append(requestedUrls, req.URL.String())
+
+					return statusResponse(200, jokeFixture(t, "chucknorris.json"))()
+				},
+			}
+
+			jokeClient := NewJokeSourceClient(NewBaseClient(httpClient, source.Address), source)
+
+			// Act
+			_, err := jokeClient.GetJokeWithQuery(context.Background(), scenario.query)
+
+			// Assert
+
+			if scenario.expectedError != nil {
+				assert.True(t, errors.Is(err, scenario.expectedError))
+
+				assert.Empty(t, requestedUrls)
+
+				return
+			}
+
+			assert.Equal(t, []string{scenario.expectedUrl}, requestedUrls)
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package restclient
+
+import (
+	"context"
+	"errors"
+	"log"
+	"my-first-telegram-bot/telegram-handler/dto"
+	"sync"
+	"time"
+)
+
+var (
+	// TelegramGlobalMessagesPerSecond is the rate Telegram allows a bot to send messages at across all chats.
+	TelegramGlobalMessagesPerSecond = 30.0
+
+	// TelegramChatMessagesPerSecond is the rate Telegram allows a bot to send messages at to a single chat.
+	TelegramChatMessagesPerSecond = 1.0
+
+	// MaxTelegramRetries is how many times a message is sent again after Telegram answers 429.
+	MaxTelegramRetries = 3
+
+	// MaxTelegramRetryAfter is the longest retry_after the client is willing to wait for,
+	// anything longer fails right away instead of outliving the invocation.
+	MaxTelegramRetryAfter = 3 * time.Second
+
+	// idleBucketTimeout is how long a chat has to stay quiet before its bucket is forgotten.
+	idleBucketTimeout = time.Minute
+)
+
+// tokenBucket hands out reservations, letting tokens go negative so callers
+// are served in the order they reserved.
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst float64, now time.Time) *tokenBucket {
+	return &tokenBucket{
+		rate:   rate,
+		burst:  burst,
+		tokens: burst,
+		last:   now,
+	}
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+
+	if now.After(b.last) {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		b.last = now
+	}
+
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+}
+
+// reserve takes a token and returns how long the caller has to wait before using it.
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+
+	b.refill(now)
+
+	b.tokens--
+
+	if b.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// pause empties the bucket so nothing is handed out before the given time.
+func (b *tokenBucket) pause(now time.Time, until time.Time) {
+
+	b.refill(now)
+
+	if waiting := until.Sub(now).Seconds() * b.rate; b.tokens > -waiting {
+		b.tokens = -waiting
+	}
+}
+
+// RateLimiter enforces Telegram's global and per chat message limits.
+type RateLimiter struct {
+	mu          sync.Mutex
+	clock       Clock
+	chatRate    float64
+	global      *tokenBucket
+	chats       map[int]*tokenBucket
+	lastCleanup time.Time
+}
+
+func NewRateLimiter(globalPerSecond float64, chatPerSecond float64, clock Clock) *RateLimiter {
+
+	now := clock.Now()
+
+	return &RateLimiter{
+		clock:       clock,
+		chatRate:    chatPerSecond,
+		global:      newTokenBucket(globalPerSecond, globalPerSecond, now),
+		chats:       map[int]*tokenBucket{},
+		lastCleanup: now,
+	}
+}
+
+// Wait blocks until a message can be sent to the chat without going over any limit,
+// or returns the context error if it is done first.
+func (l *RateLimiter) Wait(ctx context.Context, chatId int) error {
+
+	l.mu.Lock()
+
+	now := l.clock.Now()
+
+	l.cleanup(now)
+
+	chat, found := l.chats[chatId]
+
+	if !found {
+		chat = newTokenBucket(l.chatRate, 1, now)
+		l.chats[chatId] = chat
+	}
+
+	wait := l.global.reserve(now)
+
+	if chatWait := chat.reserve(now); chatWait > wait {
+		wait = chatWait
+	}
+
+	l.mu.Unlock()
+
+	if wait > 0 {
+		return l.clock.Sleep(ctx, wait)
+	}
+
+	return nil
+}
+
+// Pause holds every message back for the given duration, as Telegram asks when answering 429.
+func (l *RateLimiter) Pause(d time.Duration) {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.clock.Now()
+
+	l.global.pause(now, now.Add(d))
+}
+
+func (l *RateLimiter) cleanup(now time.Time) {
+
+	if now.Sub(l.lastCleanup) < idleBucketTimeout {
+		return
+	}
+
+	for chatId, bucket := range l.chats {
+		if now.Sub(bucket.last) >= idleBucketTimeout {
+			delete(l.chats, chatId)
+		}
+	}
+
+	l.lastCleanup = now
+}
+
+// ThrottledTelegramClient queues outgoing messages behind a RateLimiter and
+// sends them again when Telegram answers 429. Other failures are never
+// retried, as the message might already have been delivered.
+type ThrottledTelegramClient struct {
+	client  TelegramClient
+	limiter *RateLimiter
+}
+
+func NewThrottledTelegramClient(client TelegramClient, limiter *RateLimiter) *ThrottledTelegramClient {
+	return &ThrottledTelegramClient{
+		client:  client,
+		limiter: limiter,
+	}
+}
+
+func (c *ThrottledTelegramClient) PostResponse(chatId int, text string) (*dto.Message, error) {
+
+	return c.PostResponseWithContext(context.Background(), chatId, text)
+}
+
+func (c *ThrottledTelegramClient) PostResponseWithContext(ctx context.Context, chatId int, text string) (*dto.Message, error) {
+
+	return c.SendMessageWithContext(ctx, &dto.SendMessageRequest{
+		ChatId: chatId,
+		Text:   text,
+	})
+}
+
+func (c *ThrottledTelegramClient) SendMessage(message *dto.SendMessageRequest) (*dto.Message, error) {
+
+	return c.SendMessageWithContext(context.Background(), message)
+}
+
+func (c *ThrottledTelegramClient) SendMessageWithContext(ctx context.Context, message *dto.SendMessageRequest) (*dto.Message, error) {
+
+	for attempt := 0; ; attempt++ {
+
+		if err := c.limiter.Wait(ctx, message.ChatId); err != nil {
+			return nil, err
+		}
+
+		sentMessage, err := c.client.SendMessageWithContext(ctx, message)
+
+		var apiError *TelegramAPIError
+
+		if err == nil || !errors.As(err, &apiError) || !errors.Is(err, ErrTooManyRequests) {
+			return sentMessage, err
+		}
+
+		retryAfter := apiError.RetryAfter()
+
+		if attempt >= MaxTelegramRetries || retryAfter > MaxTelegramRetryAfter || !hasTimeFor(ctx, retryAfter) {
+			return nil, err
+		}
+
+		log.Printf("Telegram asked to retry after %s, attempt %d of %d", retryAfter, attempt+1, MaxTelegramRetries)
+
+		c.limiter.Pause(retryAfter)
+	}
+}
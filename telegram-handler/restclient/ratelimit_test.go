@@ -0,0 +1,200 @@
+package restclient
+
+import (
+	"context"
+	"errors"
+	"my-first-telegram-bot/telegram-handler/dto"
+	"my-first-telegram-bot/telegram-handler/utils/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+
+	t.Run("Per chat limit", func(t *testing.T) {
+
+		// Arrange
+		clock := &mocks.MockClock{Current: time.Unix(1614894279, 0)}
+
+		limiter := NewRateLimiter(30, 1, clock)
+
+		// Act
+		limiter.Wait(context.Background(), 1234)
+		limiter.Wait(context.Background(), 1234)
+		limiter.Wait(context.Background(), 1234)
+		limiter.Wait(context.Background(), 5678)
+
+		// Assert
+		assert.Equal(t, []time.Duration{time.Second, time.Second}, clock.Slept)
+	})
+
+	t.Run("Global limit", func(t *testing.T) {
+
+		// Arrange
+		clock := &mocks.MockClock{Current: time.Unix(1614894279, 0)}
+
+		limiter := NewRateLimiter(2, 1, clock)
+
+		// Act
+		limiter.Wait(context.Background(), 1)
+		limiter.Wait(context.Background(), 2)
+		limiter.Wait(context.Background(), 3)
+
+		// Assert
+		assert.Equal(t, []time.Duration{500 * time.Millisecond}, clock.Slept)
+	})
+
+	t.Run("Limits refill over time", func(t *testing.T) {
+
+		// Arrange
+		clock := &mocks.MockClock{Current: time.Unix(1614894279, 0)}
+
+		limiter := NewRateLimiter(30, 1, clock)
+
+		// Act
+		limiter.Wait(context.Background(), 1234)
+		clock.Advance(2 * time.Second)
+		limiter.Wait(context.Background(), 1234)
+
+		// Assert
+		assert.Empty(t, clock.Slept)
+	})
+
+	t.Run("Pause holds every chat back", func(t *testing.T) {
+
+		// Arrange
+		clock := &mocks.MockClock{Current: time.Unix(1614894279, 0)}
+
+		limiter := NewRateLimiter(30, 1, clock)
+
+		// Act
+		limiter.Pause(2 * time.Second)
+		limiter.Wait(context.Background(), 1234)
+
+		// Assert
+		assert.Equal(t, 1, len(clock.Slept))
+		assert.InDelta(t, float64(2*time.Second), float64(clock.Slept[0]), float64(50*time.Millisecond))
+	})
+}
+
+func TestThrottledTelegramClient(t *testing.T) {
+
+	tooManyRequests := func(retryAfter int) error {
+		return &TelegramAPIError{
+			Code:        429,
+			Description: "Too Many Requests: retry after",
+			Parameters:  dto.ResponseParameters{RetryAfter: retryAfter},
+		}
+	}
+
+	t.Run("Retry after 429", func(t *testing.T) {
+
+		// Arrange
+		clock := &mocks.MockClock{Current: time.Unix(1614894279, 0)}
+
+		attempts := 0
+
+		myMockClient := &mocks.MockBaseClient{}
+
+		myMockClient.SendMessageFunc = func(message *dto.SendMessageRequest) (*dto.Message, error) {
+
+			attempts++
+
+			if attempts == 1 {
+				return nil, tooManyRequests(2)
+			}
+
+			return &dto.Message{MessageId: 1, Chat: dto.Chat{Id: message.ChatId}}, nil
+		}
+
+		telegramClient := NewThrottledTelegramClient(myMockClient, NewRateLimiter(30, 1, clock))
+
+		// Act
+		response, err := telegramClient.PostResponse(1234, "stuff happened")
+
+		// Assert
+
+		assert.Nil(t, err)
+
+		assert.Equal(t, 1234, response.Chat.Id)
+
+		assert.Equal(t, 2, myMockClient.ReturnSendMessageCallCount)
+
+		assert.InDelta(t, float64(2*time.Second), float64(clock.Current.Sub(time.Unix(1614894279, 0))), float64(50*time.Millisecond))
+	})
+
+	t.Run("Give up on long retry after", func(t *testing.T) {
+
+		// Arrange
+		clock := &mocks.MockClock{Current: time.Unix(1614894279, 0)}
+
+		myMockClient := &mocks.MockBaseClient{}
+
+		myMockClient.SendMessageFunc = func(message *dto.SendMessageRequest) (*dto.Message, error) {
+			return nil, tooManyRequests(60)
+		}
+
+		telegramClient := NewThrottledTelegramClient(myMockClient, NewRateLimiter(30, 1, clock))
+
+		// Act
+		response, err := telegramClient.PostResponse(1234, "stuff happened")
+
+		// Assert
+
+		assert.Nil(t, response)
+
+		assert.True(t, errors.Is(err, ErrTooManyRequests))
+
+		assert.Equal(t, 1, myMockClient.ReturnSendMessageCallCount)
+
+		assert.Empty(t, clock.Slept)
+	})
+
+	t.Run("Give up after max retries", func(t *testing.T) {
+
+		// Arrange
+		clock := &mocks.MockClock{Current: time.Unix(1614894279, 0)}
+
+		myMockClient := &mocks.MockBaseClient{}
+
+		myMockClient.SendMessageFunc = func(message *dto.SendMessageRequest) (*dto.Message, error) {
+			return nil, tooManyRequests(1)
+		}
+
+		telegramClient := NewThrottledTelegramClient(myMockClient, NewRateLimiter(30, 1, clock))
+
+		// Act
+		_, err := telegramClient.PostResponse(1234, "stuff happened")
+
+		// Assert
+
+		assert.True(t, errors.Is(err, ErrTooManyRequests))
+
+		assert.Equal(t, MaxTelegramRetries+1, myMockClient.ReturnSendMessageCallCount)
+	})
+
+	t.Run("Other errors are not retried", func(t *testing.T) {
+
+		// Arrange
+		clock := &mocks.MockClock{Current: time.Unix(1614894279, 0)}
+
+		myMockClient := &mocks.MockBaseClient{}
+
+		myMockClient.SendMessageFunc = func(message *dto.SendMessageRequest) (*dto.Message, error) {
+			return nil, ErrNon200Response
+		}
+
+		telegramClient := NewThrottledTelegramClient(myMockClient, NewRateLimiter(30, 1, clock))
+
+		// Act
+		_, err := telegramClient.PostResponse(1234, "stuff happened")
+
+		// Assert
+
+		assert.Equal(t, ErrNon200Response, err)
+
+		assert.Equal(t, 1, myMockClient.ReturnSendMessageCallCount)
+	})
+}
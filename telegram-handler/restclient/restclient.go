@@ -1,51 +1,84 @@
 package restclient
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"log"
 	"my-first-telegram-bot/telegram-handler/dto"
 	"net/http"
-	"net/url"
-	"os"
-	"strconv"
-	"strings"
+	"time"
 )
 
+// DefaultTelegramApiAddress is the address of the Telegram API, followed by the bot prefix.
+const DefaultTelegramApiAddress = "https://api.telegram.org/bot"
+
 var (
-	RandomFactsAddress = "https://uselessfacts.jsph.pl/today.json?language=en"
+	UselessFactsAddress = "https://uselessfacts.jsph.pl/"
 
-	RandomJokesAddress = "http://api.icndb.com/jokes/random?limitTo=[nerdy]"
+	// FactLanguages are the languages facts can be fetched in, the first one being the default.
+	FactLanguages = []string{"en", "de"}
 
-	TelegramApi = "https://api.telegram.org/bot" + os.Getenv("TELEGRAM_API_TOKEN") + "/sendMessage"
+	// FactKinds are the kinds of facts that can be fetched, the first one being the default.
+	FactKinds = []string{dto.FactKindToday, dto.FactKindRandom}
 
-	MyFactClient FactClient = &BaseClient{
-		client: &http.Client{},
-		url:    RandomFactsAddress,
-	}
+	// ContentApiTimeout bounds every single request to the fact and joke APIs.
+	ContentApiTimeout = 2 * time.Second
 
-	MyJokeClient JokeClient = &BaseClient{
-		client: &http.Client{},
-		url:    RandomJokesAddress,
-	}
+	// TelegramApiTimeout bounds every request managing the bot, like setting its webhook.
+	TelegramApiTimeout = 10 * time.Second
+)
 
-	MyTelegramClient TelegramClient = &BaseClient{
-		client: &http.Client{},
-		url:    TelegramApi,
-	}
+var (
+	ErrUnknownFactKind     = errors.New("Unknown fact kind")
+	ErrUnknownFactLanguage = errors.New("Unknown fact language")
 )
 
 type FactClient interface {
 	GetFact() (*dto.GeneratedFact, error)
+	GetFactWithContext(ctx context.Context) (*dto.GeneratedFact, error)
+	GetFactWithQuery(ctx context.Context, query *dto.FactQuery) (*dto.GeneratedFact, error)
 }
 
 type JokeClient interface {
 	GetJoke() (*dto.GeneratedJoke, error)
+	GetJokeWithContext(ctx context.Context) (*dto.GeneratedJoke, error)
+	GetJokeWithQuery(ctx context.Context, query *dto.JokeQuery) (*dto.GeneratedJoke, error)
+	// Categories lists the categories jokes can be fetched from, if any.
+	Categories() []string
 }
 
 type TelegramClient interface {
-	PostResponse(chatId int, content string) (string, error)
+	PostResponse(chatId int, content string) (*dto.Message, error)
+	PostResponseWithContext(ctx context.Context, chatId int, content string) (*dto.Message, error)
+	SendMessage(message *dto.SendMessageRequest) (*dto.Message, error)
+	SendMessageWithContext(ctx context.Context, message *dto.SendMessageRequest) (*dto.Message, error)
+}
+
+type UpdatesClient interface {
+	GetUpdatesWithContext(ctx context.Context, request *dto.GetUpdatesRequest) ([]dto.Update, error)
+}
+
+type WebhookClient interface {
+	SetWebhookWithContext(ctx context.Context, request *dto.SetWebhookRequest) error
+	GetWebhookInfoWithContext(ctx context.Context) (*dto.WebhookInfo, error)
+	DeleteWebhookWithContext(ctx context.Context, request *dto.DeleteWebhookRequest) error
+}
+
+type CommandsClient interface {
+	SetMyCommandsWithContext(ctx context.Context, request *dto.SetMyCommandsRequest) error
+}
+
+type CallbackClient interface {
+	AnswerCallbackQueryWithContext(ctx context.Context, request *dto.AnswerCallbackQueryRequest) error
+}
+
+type IdentityClient interface {
+	GetMeWithContext(ctx context.Context) (*dto.User, error)
 }
 
 type HttpClient interface {
@@ -53,16 +86,107 @@ type HttpClient interface {
 }
 
 type BaseClient struct {
-	client HttpClient
-	url    string
+	client      HttpClient
+	url         string
+	retryPolicy RetryPolicy
+	clock       Clock
+	breaker     *CircuitBreaker
+	headers     map[string]string
+}
+
+func NewBaseClient(client HttpClient, url string) *BaseClient {
+	return &BaseClient{
+		client:      client,
+		url:         url,
+		retryPolicy: NoRetryPolicy,
+		clock:       SystemClock,
+	}
+}
+
+// NewFactClient creates a client for the random facts API.
+func NewFactClient() *BaseClient {
+	return NewBaseClient(&http.Client{Timeout: ContentApiTimeout}, UselessFactsAddress).
+		WithRetryPolicy(DefaultRetryPolicy).
+		WithCircuitBreaker(NewCircuitBreaker("facts", DefaultCircuitBreakerSettings, SystemClock))
+}
+
+// WithRetryPolicy sets how failed GET requests of the client are retried.
+func (cb *BaseClient) WithRetryPolicy(policy RetryPolicy) *BaseClient {
+	cb.retryPolicy = policy
+	return cb
+}
+
+// WithClock sets the clock the client waits on between retries.
+func (cb *BaseClient) WithClock(clock Clock) *BaseClient {
+	cb.clock = clock
+	return cb
+}
+
+// WithCircuitBreaker guards the GET requests of the client with the breaker, failing
+// them with ErrCircuitOpen while it is open.
+func (cb *BaseClient) WithCircuitBreaker(breaker *CircuitBreaker) *BaseClient {
+	cb.breaker = breaker
+	return cb
+}
+
+// WithHeader sets a header sent along with every GET request of the client.
+func (cb *BaseClient) WithHeader(name string, value string) *BaseClient {
+
+	if cb.headers == nil {
+		cb.headers = map[string]string{}
+	}
+
+	cb.headers[name] = value
+
+	return cb
+}
+
+// NewTelegramClient creates a client that sends messages on behalf of the bot owning the token,
+// through the Telegram API at the address, or DefaultTelegramApiAddress when it is empty.
+func NewTelegramClient(address string, token string) *BaseClient {
+	return NewBaseClient(&http.Client{}, telegramMethodUrl(address, token, "sendMessage"))
+}
+
+// NewTelegramUpdatesClient creates a client that fetches the updates of the bot owning the token,
+// through the Telegram API at the address, or DefaultTelegramApiAddress when it is empty.
+// Requests are bounded by their context only, since getUpdates holds them for as long as it is asked to.
+func NewTelegramUpdatesClient(address string, token string) *BaseClient {
+	return NewBaseClient(&http.Client{}, telegramMethodUrl(address, token, "getUpdates"))
+}
+
+func telegramMethodUrl(address string, token string, method string) string {
+
+	if address == "" {
+		address = DefaultTelegramApiAddress
+	}
+
+	return address + token + "/" + method
 }
 
 func (cb *BaseClient) GetFact() (*dto.GeneratedFact, error) {
 
-	r, err := get(cb)
+	return cb.GetFactWithContext(context.Background())
+}
+
+// GetFactWithContext fetches a fact, giving up on retries and requests once the context is done.
+func (cb *BaseClient) GetFactWithContext(ctx context.Context) (*dto.GeneratedFact, error) {
+
+	return cb.GetFactWithQuery(ctx, &dto.FactQuery{})
+}
+
+// GetFactWithQuery fetches a fact of the kind and in the language of the query.
+func (cb *BaseClient) GetFactWithQuery(ctx context.Context, query *dto.FactQuery) (*dto.GeneratedFact, error) {
 
 	factToReturn := &dto.GeneratedFact{}
 
+	factUrl, err := cb.factUrl(query)
+
+	if err != nil {
+		return factToReturn, err
+	}
+
+	r, err := get(ctx, cb, factUrl)
+
 	if err != nil {
 		return factToReturn, err
 	}
@@ -88,80 +212,133 @@ func (cb *BaseClient) GetFact() (*dto.GeneratedFact, error) {
 	return factToReturn, nil
 }
 
-func (cb *BaseClient) GetJoke() (*dto.GeneratedJoke, error) {
+// factUrl builds the address of the fact the query asks for, like today.json?language=en.
+func (cb *BaseClient) factUrl(query *dto.FactQuery) (string, error) {
 
-	r, err := get(cb)
+	kind, language := FactKinds[0], FactLanguages[0]
 
-	jokeToReturn := &dto.GeneratedJoke{}
+	if query.Kind != "" {
+		kind = query.Kind
+	}
 
-	if err != nil {
-		return jokeToReturn, err
+	if query.Language != "" {
+		language = query.Language
 	}
 
-	defer r.Body.Close()
+	if !contains(FactKinds, kind) {
+		return "", fmt.Errorf("%w %q, the known ones are %v", ErrUnknownFactKind, kind, FactKinds)
+	}
 
-	body, err := ioutil.ReadAll(r.Body)
+	if !contains(FactLanguages, language) {
+		return "", fmt.Errorf("%w %q, the known ones are %v", ErrUnknownFactLanguage, language, FactLanguages)
+	}
 
-	if err != nil {
-		return jokeToReturn, err
+	return cb.url + kind + ".json?language=" + language, nil
+}
+
+func contains(values []string, value string) bool {
+
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
 	}
 
-	err = json.Unmarshal([]byte(body), &jokeToReturn)
+	return false
+}
 
-	if err != nil {
-		return jokeToReturn, err
+// get sends a GET request to the address through the circuit breaker of the client, if any.
+func get(ctx context.Context, bc *BaseClient, url string) (*http.Response, error) {
+
+	if bc.breaker == nil {
+		return getWithRetries(ctx, bc, url)
 	}
 
-	if len(jokeToReturn.Value.Joke) == 0 {
-		return jokeToReturn, err
+	if err := bc.breaker.Allow(); err != nil {
+		return nil, err
 	}
 
-	return jokeToReturn, nil
+	response, err := getWithRetries(ctx, bc, url)
+
+	bc.breaker.Record(err)
+
+	return response, err
 }
 
-func (cb *BaseClient) PostResponse(chatId int, text string) (string, error) {
+// getWithRetries sends a GET request, retrying it as the retry policy of the client allows.
+// Any response that isn't 200 once retries are exhausted is reported as ErrNon200Response.
+// No retry is attempted when the context deadline comes before the backoff is over.
+func getWithRetries(ctx context.Context, bc *BaseClient, url string) (*http.Response, error) {
 
-	log.Printf("Sending %s to chat_id: %d", text, chatId)
+	for attempt := 1; ; attempt++ {
 
-	response, err := postForm(
-		cb,
-		url.Values{
-			"chat_id": {strconv.Itoa(chatId)},
-			"text":    {text},
-		})
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 
-	if err != nil {
-		return "", err
-	}
+		if err != nil {
+			return nil, err
+		}
 
-	defer response.Body.Close()
+		for name, value := range bc.headers {
+			request.Header.Set(name, value)
+		}
 
-	var bodyBytes, errRead = ioutil.ReadAll(response.Body)
+		response, err := bc.client.Do(request)
 
-	if errRead != nil {
+		if attempt >= bc.retryPolicy.MaxAttempts || ctx.Err() != nil || !bc.retryPolicy.shouldRetry(response, err) {
+			return checkStatus(response, err)
+		}
 
-		return "", err
-	}
+		backoff := bc.retryPolicy.backoff(attempt)
 
-	bodyString := string(bodyBytes)
+		if !hasTimeFor(ctx, backoff) {
+			log.Printf("Attempt %d to %s failed with no time left to retry", attempt, url)
 
-	return bodyString, nil
-}
+			return checkStatus(response, err)
+		}
 
-func get(bc *BaseClient) (*http.Response, error) {
+		if err == nil {
+			io.Copy(ioutil.Discard, response.Body)
+			response.Body.Close()
 
-	request, err := http.NewRequest(http.MethodGet, bc.url, nil)
+			log.Printf("Attempt %d of %d to %s got status %d, retrying", attempt, bc.retryPolicy.MaxAttempts, url, response.StatusCode)
+		} else {
+			log.Printf("Attempt %d of %d to %s failed, retrying: %v", attempt, bc.retryPolicy.MaxAttempts, url, err)
+		}
+
+		if err := bc.clockOrDefault().Sleep(ctx, backoff); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func checkStatus(response *http.Response, err error) (*http.Response, error) {
 
 	if err != nil {
 		return nil, err
 	}
 
-	return bc.client.Do(request)
+	if response.StatusCode != http.StatusOK {
+		io.Copy(ioutil.Discard, response.Body)
+		response.Body.Close()
+
+		return nil, ErrNon200Response
+	}
+
+	return response, nil
 }
 
-func post(cb *BaseClient, contentType string, body io.Reader) (*http.Response, error) {
+func (cb *BaseClient) clockOrDefault() Clock {
+
+	if cb.clock == nil {
+		return SystemClock
+	}
+
+	return cb.clock
+}
 
-	req, err := http.NewRequest(http.MethodPost, cb.url, body)
+func post(ctx context.Context, cb *BaseClient, contentType string, body io.Reader) (*http.Response, error) {
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cb.url, body)
 
 	if err != nil {
 		return nil, err
@@ -172,8 +349,13 @@ func post(cb *BaseClient, contentType string, body io.Reader) (*http.Response, e
 	return cb.client.Do(req)
 }
 
-func postForm(cb *BaseClient, data url.Values) (resp *http.Response, err error) {
+func postJson(ctx context.Context, cb *BaseClient, data interface{}) (resp *http.Response, err error) {
+
+	payload, err := json.Marshal(data)
 
-	return post(cb, "application/x-www-form-urlencoded", strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, err
+	}
 
+	return post(ctx, cb, "application/json", bytes.NewReader(payload))
 }
@@ -2,8 +2,11 @@ package restclient
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"io/ioutil"
+	"my-first-telegram-bot/telegram-handler/dto"
 	"my-first-telegram-bot/telegram-handler/utils/mocks"
 	"net/http"
 	"testing"
@@ -73,7 +76,7 @@ func TestFailedJokeRequest(t *testing.T) {
 
 	t.Run("Error joke request", func(t *testing.T) {
 
-		expectedId := 0
+		expectedId := ""
 		expectedjoke := ""
 		expectedType := ""
 		expectedCategories := []string(nil)
@@ -90,9 +93,9 @@ func TestFailedJokeRequest(t *testing.T) {
 			},
 		}
 
-		jokeClient := &BaseClient{
+		jokeClient := NewJokeSourceClient(&BaseClient{
 			client: jokeHttpErrClient,
-			url:    "temp"}
+			url:    "temp"}, JokeSources["chucknorris"])
 
 		// Act
 		response, err := jokeClient.GetJoke()
@@ -150,9 +153,7 @@ func TestFailedTelegramRequest(t *testing.T) {
 
 		assert.NotNil(t, err)
 
-		assert.EqualValues(t,
-			expectedResponseText,
-			response)
+		assert.Nil(t, response)
 
 	})
 
@@ -162,10 +163,10 @@ func TestSuccessPostTelegram(t *testing.T) {
 
 	t.Run("Successful post to telegram request", func(t *testing.T) {
 
-		expectedResponseText := "{\"ok\": true,\"result\": {\"message_id\": 45,\"from\": {\"id\": 1025326803,\"is_bot\": true,\"first_name\": \"MyDailyFact\",\"username\": \"majoFFper_bot\"},\"chat\": {\"id\": 690639026,\"first_name\": \"Mário\",\"type\": \"private\"},\"date\": 1615076796,\"text\": \"Product Owners never ask Chuck Norris for more features. They ask for mercy.\"}}"
+		rawResponse := "{\"ok\": true,\"result\": {\"message_id\": 45,\"from\": {\"id\": 1025326803,\"is_bot\": true,\"first_name\": \"MyDailyFact\",\"username\": \"majoFFper_bot\"},\"chat\": {\"id\": 690639026,\"first_name\": \"Mário\",\"type\": \"private\"},\"date\": 1615076796,\"text\": \"Product Owners never ask Chuck Norris for more features. They ask for mercy.\"}}"
 
 		// Arrange
-		r := ioutil.NopCloser(bytes.NewReader([]byte(expectedResponseText)))
+		r := ioutil.NopCloser(bytes.NewReader([]byte(rawResponse)))
 
 		telegramHttSuccessClient := &mocks.MockHttpClient{
 			DoFunc: func(*http.Request) (*http.Response, error) {
@@ -190,8 +191,20 @@ func TestSuccessPostTelegram(t *testing.T) {
 		// Assert
 
 		assert.EqualValues(t,
-			expectedResponseText,
-			response)
+			45,
+			response.MessageId)
+
+		assert.EqualValues(t,
+			690639026,
+			response.Chat.Id)
+
+		assert.EqualValues(t,
+			"Product Owners never ask Chuck Norris for more features. They ask for mercy.",
+			response.Text)
+
+		assert.EqualValues(t,
+			"majoFFper_bot",
+			response.From.Username)
 
 	})
 
@@ -201,12 +214,12 @@ func TestSuccessJokeRequest(t *testing.T) {
 
 	t.Run("Successful joke request", func(t *testing.T) {
 
-		rawResponse := "{\"type\": \"success\",\"value\": {\"id\": 479,\"joke\": \"Chuck Norris does not need to know about class factory pattern. He can instantiate interfaces.\",\"categories\": [\"nerdy\"]}}"
+		rawResponse := "{\"categories\": [\"dev\"],\"id\": \"bq6xyzqnthmw4ghlf4mhqa\",\"url\": \"https://api.chucknorris.io/jokes/bq6xyzqnthmw4ghlf4mhqa\",\"value\": \"Chuck Norris does not need to know about class factory pattern. He can instantiate interfaces.\"}"
 
-		expectedId := 479
+		expectedId := "bq6xyzqnthmw4ghlf4mhqa"
 		expectedjoke := "Chuck Norris does not need to know about class factory pattern. He can instantiate interfaces."
-		expectedType := "success"
-		expectedCategories := []string([]string{"nerdy"})
+		expectedType := "single"
+		expectedCategories := []string([]string{"dev"})
 
 		// Arrange
 		r := ioutil.NopCloser(bytes.NewReader([]byte(rawResponse)))
@@ -220,9 +233,9 @@ func TestSuccessJokeRequest(t *testing.T) {
 			},
 		}
 
-		jokeClient := &BaseClient{
+		jokeClient := NewJokeSourceClient(&BaseClient{
 			client: jokeHttSuccessClient,
-			url:    "temp"}
+			url:    "temp"}, JokeSources["chucknorris"])
 
 		// Act
 		response, err := jokeClient.GetJoke()
@@ -313,3 +326,134 @@ func TestSuccessFactRequest(t *testing.T) {
 	})
 
 }
+
+func TestFactQuery(t *testing.T) {
+
+	scenarios := []struct {
+		name          string
+		query         *dto.FactQuery
+		expectedUrl   string
+		expectedError error
+	}{
+		{
+			name:        "Defaults to the fact of the day in english",
+			query:       &dto.FactQuery{},
+			expectedUrl: "https://uselessfacts.jsph.pl/today.json?language=en",
+		},
+		{
+			name:        "Random fact in german",
+			query:       &dto.FactQuery{Kind: dto.FactKindRandom, Language: "de"},
+			expectedUrl: "https://uselessfacts.jsph.pl/random.json?language=de",
+		},
+		{
+			name:          "Unknown language",
+			query:         &dto.FactQuery{Language: "pt"},
+			expectedError: ErrUnknownFactLanguage,
+		},
+		{
+			name:          "Unknown kind",
+			query:         &dto.FactQuery{Kind: "yesterday"},
+			expectedError: ErrUnknownFactKind,
+		},
+	}
+
+	for _, scenario := range scenarios {
+
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+
+			var requestedUrls []string
+
+			// Arrange
+			factClient := NewBaseClient(&mocks.MockHttpClient{
+				DoFunc: func(req *http.Request) (*http.Response, error) {
+
+					requestedUrls = append(requestedUrls, req.URL.String())
+
+					return statusResponse(200, "{\"text\": \"Kartoffeln\"}")()
+				},
+			}, UselessFactsAddress)
+
+			// Act
+			_, err := factClient.GetFactWithQuery(context.Background(), scenario.query)
+
+			// Assert
+
+			if scenario.expectedError != nil {
+				assert.True(t, errors.Is(err, scenario.expectedError))
+
+				assert.Empty(t, requestedUrls)
+
+				return
+			}
+
+			assert.Nil(t, err)
+
+			assert.Equal(t, []string{scenario.expectedUrl}, requestedUrls)
+		})
+	}
+}
+
+func TestSendMessageRequest(t *testing.T) {
+
+	t.Run("Send message options are posted as JSON", func(t *testing.T) {
+
+		var (
+			sentContentType string
+			sentBody        map[string]interface{}
+		)
+
+		// Arrange
+		telegramHttpClient := &mocks.MockHttpClient{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+
+				sentContentType = req.Header.Get("Content-Type")
+
+				if err := json.NewDecoder(req.Body).Decode(&sentBody); err != nil {
+					t.Fatal("Can't run test scenario")
+				}
+
+				return &http.Response{
+					StatusCode: 200,
+					Body:       ioutil.NopCloser(bytes.NewReader([]byte("{\"ok\": true}"))),
+				}, nil
+			},
+		}
+
+		telegramClient := &BaseClient{
+			client: telegramHttpClient,
+			url:    "temp"}
+
+		// Act
+		_, err := telegramClient.SendMessage(&dto.SendMessageRequest{
+			ChatId:                123,
+			Text:                  "<b>stuff</b> happened",
+			ParseMode:             dto.ParseModeHTML,
+			ReplyToMessageId:      45,
+			DisableWebPagePreview: true,
+			ReplyMarkup: &dto.InlineKeyboardMarkup{
+				InlineKeyboard: [][]dto.InlineKeyboardButton{{{Text: "More", CallbackData: "more"}}},
+			},
+		})
+
+		// Assert
+
+		assert.Nil(t, err)
+
+		assert.Equal(t, "application/json", sentContentType)
+
+		assert.EqualValues(t, map[string]interface{}{
+			"chat_id":                  float64(123),
+			"text":                     "<b>stuff</b> happened",
+			"parse_mode":               "HTML",
+			"reply_to_message_id":      float64(45),
+			"disable_web_page_preview": true,
+			"reply_markup": map[string]interface{}{
+				"inline_keyboard": []interface{}{
+					[]interface{}{map[string]interface{}{"text": "More", "callback_data": "more"}},
+				},
+			},
+		}, sentBody)
+	})
+}
@@ -0,0 +1,89 @@
+package restclient
+
+import (
+	"math"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy decides how many times and how often an idempotent request is sent again.
+type RetryPolicy struct {
+	// MaxAttempts counts the first attempt too, anything below 2 disables retries.
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	// Jitter randomizes every backoff by up to this fraction of it, in both directions.
+	Jitter float64
+	// RetryableStatusClasses lists the status code classes that are retried, 5 meaning every 5xx.
+	RetryableStatusClasses []int
+	// RetryableStatusCodes lists single status codes that are retried outside of those classes.
+	RetryableStatusCodes []int
+}
+
+var (
+	DefaultRetryPolicy = RetryPolicy{
+		MaxAttempts:            3,
+		InitialBackoff:         100 * time.Millisecond,
+		MaxBackoff:             time.Second,
+		Multiplier:             2,
+		Jitter:                 0.2,
+		RetryableStatusClasses: []int{5},
+		RetryableStatusCodes:   []int{http.StatusRequestTimeout, http.StatusTooManyRequests},
+	}
+
+	NoRetryPolicy = RetryPolicy{
+		MaxAttempts: 1,
+	}
+)
+
+func (p RetryPolicy) isRetryableStatus(statusCode int) bool {
+
+	for _, class := range p.RetryableStatusClasses {
+		if statusCode/100 == class {
+			return true
+		}
+	}
+
+	for _, code := range p.RetryableStatusCodes {
+		if statusCode == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// shouldRetry tells whether another attempt may succeed where this one failed.
+// Transport errors, like connection resets, are always worth another try.
+func (p RetryPolicy) shouldRetry(response *http.Response, err error) bool {
+
+	if err != nil {
+		return true
+	}
+
+	return p.isRetryableStatus(response.StatusCode)
+}
+
+// backoff is how long to wait after the given failed attempt, starting at 1.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+
+	multiplier := p.Multiplier
+
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	backoff := float64(p.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+
+	if p.MaxBackoff > 0 && backoff > float64(p.MaxBackoff) {
+		backoff = float64(p.MaxBackoff)
+	}
+
+	if p.Jitter > 0 {
+		backoff *= 1 + p.Jitter*(2*rand.Float64()-1)
+	}
+
+	return time.Duration(backoff)
+}
@@ -0,0 +1,270 @@
+package restclient
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"my-first-telegram-bot/telegram-handler/utils/mocks"
+	"net"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testRetryPolicy = RetryPolicy{
+	MaxAttempts:            3,
+	InitialBackoff:         100 * time.Millisecond,
+	MaxBackoff:             time.Second,
+	Multiplier:             2,
+	RetryableStatusClasses: []int{5},
+}
+
+func sequenceHttpClient(responses ...func() (*http.Response, error)) *mocks.MockHttpClient {
+
+	calls := 0
+
+	return &mocks.MockHttpClient{
+		DoFunc: func(*http.Request) (*http.Response, error) {
+			response := responses[calls]
+			calls++
+			return response()
+		},
+	}
+}
+
+func statusResponse(statusCode int, body string) func() (*http.Response, error) {
+	return func() (*http.Response, error) {
+		return &http.Response{
+			StatusCode: statusCode,
+			Body:       ioutil.NopCloser(bytes.NewReader([]byte(body))),
+		}, nil
+	}
+}
+
+func connectionReset() (*http.Response, error) {
+	return nil, &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+}
+
+func TestFactRequestRetries(t *testing.T) {
+
+	rawFactResponse := "{\"id\": \"96221b11-8a37-4495-baf0-134be4feffc1\", \"text\": \"To Ensure Promptness, one is expected to pay beyond the value of service – hence the later abbreviation: T.I.P.\", \"language\": \"en\"}"
+
+	t.Run("Transient 502 and connection reset", func(t *testing.T) {
+
+		// Arrange
+		clock := &mocks.MockClock{}
+
+		factClient := NewBaseClient(
+			sequenceHttpClient(statusResponse(502, "Bad Gateway"), connectionReset, statusResponse(200, rawFactResponse)),
+			"temp").
+			WithRetryPolicy(testRetryPolicy).
+			WithClock(clock)
+
+		// Act
+		response, err := factClient.GetFact()
+
+		// Assert
+
+		assert.Nil(t, err)
+
+		assert.EqualValues(t, "96221b11-8a37-4495-baf0-134be4feffc1", response.ID)
+
+		assert.Equal(t, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond}, clock.Slept)
+	})
+
+	t.Run("Retries exhausted", func(t *testing.T) {
+
+		// Arrange
+		clock := &mocks.MockClock{}
+
+		factClient := NewBaseClient(
+			sequenceHttpClient(statusResponse(502, ""), statusResponse(503, ""), statusResponse(502, "")),
+			"temp").
+			WithRetryPolicy(testRetryPolicy).
+			WithClock(clock)
+
+		// Act
+		_, err := factClient.GetFact()
+
+		// Assert
+
+		assert.Equal(t, ErrNon200Response, err)
+
+		assert.Equal(t, 2, len(clock.Slept))
+	})
+
+	t.Run("Non retryable status", func(t *testing.T) {
+
+		// Arrange
+		clock := &mocks.MockClock{}
+
+		factClient := NewBaseClient(
+			sequenceHttpClient(statusResponse(404, "Not Found")),
+			"temp").
+			WithRetryPolicy(testRetryPolicy).
+			WithClock(clock)
+
+		// Act
+		_, err := factClient.GetFact()
+
+		// Assert
+
+		assert.Equal(t, ErrNon200Response, err)
+
+		assert.Empty(t, clock.Slept)
+	})
+}
+
+func TestJokeRequestRetries(t *testing.T) {
+
+	t.Run("Connection reset then success", func(t *testing.T) {
+
+		rawResponse := "{\"categories\": [\"dev\"],\"id\": \"bq6xyzqnthmw4ghlf4mhqa\",\"value\": \"Chuck Norris can instantiate interfaces.\"}"
+
+		// Arrange
+		clock := &mocks.MockClock{}
+
+		jokeClient := NewJokeSourceClient(
+			NewBaseClient(
+				sequenceHttpClient(connectionReset, statusResponse(200, rawResponse)),
+				"temp").
+				WithRetryPolicy(testRetryPolicy).
+				WithClock(clock),
+			JokeSources["chucknorris"])
+
+		// Act
+		response, err := jokeClient.GetJoke()
+
+		// Assert
+
+		assert.Nil(t, err)
+
+		assert.EqualValues(t, "bq6xyzqnthmw4ghlf4mhqa", response.Value.ID)
+
+		assert.Equal(t, []time.Duration{100 * time.Millisecond}, clock.Slept)
+	})
+
+	t.Run("No retry policy", func(t *testing.T) {
+
+		// Arrange
+		clock := &mocks.MockClock{}
+
+		jokeClient := NewJokeSourceClient(
+			NewBaseClient(sequenceHttpClient(connectionReset), "temp").
+				WithClock(clock),
+			JokeSources["chucknorris"])
+
+		// Act
+		_, err := jokeClient.GetJoke()
+
+		// Assert
+
+		assert.NotNil(t, err)
+
+		assert.Empty(t, clock.Slept)
+	})
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+
+	t.Run("Backoff grows and is capped", func(t *testing.T) {
+
+		policy := RetryPolicy{
+			InitialBackoff: 100 * time.Millisecond,
+			MaxBackoff:     300 * time.Millisecond,
+			Multiplier:     2,
+		}
+
+		assert.Equal(t, 100*time.Millisecond, policy.backoff(1))
+		assert.Equal(t, 200*time.Millisecond, policy.backoff(2))
+		assert.Equal(t, 300*time.Millisecond, policy.backoff(3))
+	})
+
+	t.Run("Backoff jitter stays in range", func(t *testing.T) {
+
+		policy := RetryPolicy{
+			InitialBackoff: 100 * time.Millisecond,
+			Multiplier:     2,
+			Jitter:         0.5,
+		}
+
+		for i := 0; i < 100; i++ {
+			backoff := policy.backoff(2)
+
+			assert.True(t, backoff >= 100*time.Millisecond && backoff <= 300*time.Millisecond, backoff)
+		}
+	})
+
+	t.Run("Retryable statuses", func(t *testing.T) {
+
+		assert.True(t, DefaultRetryPolicy.isRetryableStatus(502))
+		assert.True(t, DefaultRetryPolicy.isRetryableStatus(429))
+		assert.False(t, DefaultRetryPolicy.isRetryableStatus(404))
+		assert.False(t, DefaultRetryPolicy.isRetryableStatus(200))
+	})
+}
+
+func TestRequestRetriesWithContext(t *testing.T) {
+
+	t.Run("No retry past the deadline", func(t *testing.T) {
+
+		// Arrange
+		clock := &mocks.MockClock{}
+
+		factClient := NewBaseClient(
+			sequenceHttpClient(statusResponse(502, ""), statusResponse(200, "")),
+			"temp").
+			WithRetryPolicy(RetryPolicy{
+				MaxAttempts:            3,
+				InitialBackoff:         time.Second,
+				RetryableStatusClasses: []int{5},
+			}).
+			WithClock(clock)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		// Act
+		_, err := factClient.GetFactWithContext(ctx)
+
+		// Assert
+
+		assert.Equal(t, ErrNon200Response, err)
+
+		assert.Empty(t, clock.Slept)
+	})
+
+	t.Run("Cancelled context", func(t *testing.T) {
+
+		// Arrange
+		calls := 0
+
+		jokeClient := NewJokeSourceClient(
+			NewBaseClient(
+				&mocks.MockHttpClient{
+					DoFunc: func(req *http.Request) (*http.Response, error) {
+						calls++
+						return nil, req.Context().Err()
+					},
+				},
+				"temp").
+				WithRetryPolicy(testRetryPolicy).
+				WithClock(&mocks.MockClock{}),
+			JokeSources["chucknorris"])
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// Act
+		_, err := jokeClient.GetJokeWithContext(ctx)
+
+		// Assert
+
+		assert.Equal(t, context.Canceled, err)
+
+		assert.Equal(t, 1, calls)
+	})
+}
@@ -0,0 +1,158 @@
+package restclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"my-first-telegram-bot/telegram-handler/dto"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNon200Response  = errors.New("Non 200 Response found")
+	ErrChatNotFound    = errors.New("Chat not found")
+	ErrBotBlocked      = errors.New("Bot was blocked by the user")
+	ErrTooManyRequests = errors.New("Too many requests")
+)
+
+// TelegramAPIError is returned when Telegram answers a method call with ok set to false.
+// Use errors.Is with ErrChatNotFound, ErrBotBlocked or ErrTooManyRequests to tell the common failures apart.
+type TelegramAPIError struct {
+	Code        int
+	Description string
+	Parameters  dto.ResponseParameters
+}
+
+func (e *TelegramAPIError) Error() string {
+	return fmt.Sprintf("Telegram API error %d: %s", e.Code, e.Description)
+}
+
+func (e *TelegramAPIError) Is(target error) bool {
+
+	description := strings.ToLower(e.Description)
+
+	switch target {
+	case ErrChatNotFound:
+		return e.Code == http.StatusBadRequest && strings.Contains(description, "chat not found")
+	case ErrBotBlocked:
+		return e.Code == http.StatusForbidden && strings.Contains(description, "bot was blocked")
+	case ErrTooManyRequests:
+		return e.Code == http.StatusTooManyRequests
+	}
+
+	return false
+}
+
+// RetryAfter is how long Telegram asks to wait before sending the request again.
+func (e *TelegramAPIError) RetryAfter() time.Duration {
+	return time.Duration(e.Parameters.RetryAfter) * time.Second
+}
+
+func (cb *BaseClient) PostResponse(chatId int, text string) (*dto.Message, error) {
+
+	return cb.PostResponseWithContext(context.Background(), chatId, text)
+}
+
+func (cb *BaseClient) PostResponseWithContext(ctx context.Context, chatId int, text string) (*dto.Message, error) {
+
+	return cb.SendMessageWithContext(ctx, &dto.SendMessageRequest{
+		ChatId: chatId,
+		Text:   text,
+	})
+}
+
+// SendMessage posts a message with all of its sendMessage options as JSON and returns the message Telegram sent.
+func (cb *BaseClient) SendMessage(message *dto.SendMessageRequest) (*dto.Message, error) {
+
+	return cb.SendMessageWithContext(context.Background(), message)
+}
+
+func (cb *BaseClient) SendMessageWithContext(ctx context.Context, message *dto.SendMessageRequest) (*dto.Message, error) {
+
+	log.Printf("Sending %s to chat_id: %d", message.Text, message.ChatId)
+
+	sentMessage := &dto.Message{}
+
+	if err := callMethod(ctx, cb, message, sentMessage); err != nil {
+		return nil, err
+	}
+
+	return sentMessage, nil
+}
+
+// GetUpdatesWithContext fetches the pending updates of the bot, waiting up to the request timeout for new ones.
+func (cb *BaseClient) GetUpdatesWithContext(ctx context.Context, request *dto.GetUpdatesRequest) ([]dto.Update, error) {
+
+	var updates []dto.Update
+
+	if err := callMethod(ctx, cb, request, &updates); err != nil {
+		return nil, err
+	}
+
+	return updates, nil
+}
+
+// callMethod posts the parameters of a Telegram method as JSON and decodes its result, if any, into result.
+func callMethod(ctx context.Context, cb *BaseClient, parameters interface{}, result interface{}) error {
+
+	response, err := postJson(ctx, cb, parameters)
+
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	return decodeTelegramResponse(response, result)
+}
+
+// decodeTelegramResponse unwraps the Telegram response envelope into result,
+// or into a TelegramAPIError when the call wasn't successful.
+func decodeTelegramResponse(response *http.Response, result interface{}) error {
+
+	body, err := ioutil.ReadAll(response.Body)
+
+	if err != nil {
+		return err
+	}
+
+	var envelope dto.Response
+
+	if err := json.Unmarshal(body, &envelope); err != nil {
+
+		if response.StatusCode != http.StatusOK {
+			return ErrNon200Response
+		}
+
+		return err
+	}
+
+	if !envelope.Ok {
+
+		apiError := &TelegramAPIError{
+			Code:        envelope.ErrorCode,
+			Description: envelope.Description,
+		}
+
+		if apiError.Code == 0 {
+			apiError.Code = response.StatusCode
+		}
+
+		if envelope.Parameters != nil {
+			apiError.Parameters = *envelope.Parameters
+		}
+
+		return apiError
+	}
+
+	if result == nil || len(envelope.Result) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(envelope.Result, result)
+}
@@ -0,0 +1,209 @@
+package restclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"my-first-telegram-bot/telegram-handler/dto"
+	"my-first-telegram-bot/telegram-handler/utils/mocks"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTelegramErrorResponses(t *testing.T) {
+
+	scenarios := []struct {
+		name             string
+		statusCode       int
+		rawResponse      string
+		expectedError    error
+		expectedCode     int
+		expectedRetry    time.Duration
+		notExpectedError error
+	}{
+		{
+			name:             "Chat not found",
+			statusCode:       400,
+			rawResponse:      "{\"ok\": false,\"error_code\": 400,\"description\": \"Bad Request: chat not found\"}",
+			expectedError:    ErrChatNotFound,
+			expectedCode:     400,
+			notExpectedError: ErrBotBlocked,
+		},
+		{
+			name:             "Bot blocked",
+			statusCode:       403,
+			rawResponse:      "{\"ok\": false,\"error_code\": 403,\"description\": \"Forbidden: bot was blocked by the user\"}",
+			expectedError:    ErrBotBlocked,
+			expectedCode:     403,
+			notExpectedError: ErrTooManyRequests,
+		},
+		{
+			name:             "Too many requests",
+			statusCode:       429,
+			rawResponse:      "{\"ok\": false,\"error_code\": 429,\"description\": \"Too Many Requests: retry after 7\",\"parameters\": {\"retry_after\": 7}}",
+			expectedError:    ErrTooManyRequests,
+			expectedCode:     429,
+			expectedRetry:    7 * time.Second,
+			notExpectedError: ErrChatNotFound,
+		},
+	}
+
+	for _, scenario := range scenarios {
+
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+
+			// Arrange
+			telegramHttpClient := &mocks.MockHttpClient{
+				DoFunc: func(*http.Request) (*http.Response, error) {
+					return &http.Response{
+						StatusCode: scenario.statusCode,
+						Body:       ioutil.NopCloser(bytes.NewReader([]byte(scenario.rawResponse))),
+					}, nil
+				},
+			}
+
+			telegramClient := &BaseClient{
+				client: telegramHttpClient,
+				url:    "temp"}
+
+			// Act
+			response, err := telegramClient.PostResponse(123, "stuff happened")
+
+			// Assert
+
+			assert.Nil(t, response)
+
+			assert.True(t, errors.Is(err, scenario.expectedError))
+
+			assert.False(t, errors.Is(err, scenario.notExpectedError))
+
+			var apiError *TelegramAPIError
+
+			assert.True(t, errors.As(err, &apiError))
+
+			assert.Equal(t, scenario.expectedCode, apiError.Code)
+
+			assert.Equal(t, scenario.expectedRetry, apiError.RetryAfter())
+		})
+	}
+}
+
+func TestTelegramNon200Response(t *testing.T) {
+
+	t.Run("Non JSON error response", func(t *testing.T) {
+
+		// Arrange
+		telegramHttpClient := &mocks.MockHttpClient{
+			DoFunc: func(*http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: 502,
+					Body:       ioutil.NopCloser(bytes.NewReader([]byte("<html>Bad Gateway</html>"))),
+				}, nil
+			},
+		}
+
+		telegramClient := &BaseClient{
+			client: telegramHttpClient,
+			url:    "temp"}
+
+		// Act
+		response, err := telegramClient.PostResponse(123, "stuff happened")
+
+		// Assert
+
+		assert.Nil(t, response)
+
+		assert.Equal(t, ErrNon200Response, err)
+	})
+}
+
+func TestGetUpdates(t *testing.T) {
+
+	t.Run("Updates are fetched from the offset", func(t *testing.T) {
+
+		var sentBody map[string]interface{}
+
+		rawResponse := "{\"ok\": true,\"result\": [{\"update_id\": 10,\"message\": {\"message_id\": 1,\"chat\": {\"id\": 1234,\"type\": \"private\"},\"text\": \"/fact\"}},{\"update_id\": 11,\"edited_message\": {\"message_id\": 1,\"chat\": {\"id\": 1234,\"type\": \"private\"},\"text\": \"/joke\"}}]}"
+
+		// Arrange
+		telegramHttpClient := &mocks.MockHttpClient{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+
+				if err := json.NewDecoder(req.Body).Decode(&sentBody); err != nil {
+					t.Fatal("Can't run test scenario")
+				}
+
+				return &http.Response{
+					StatusCode: 200,
+					Body:       ioutil.NopCloser(bytes.NewReader([]byte(rawResponse))),
+				}, nil
+			},
+		}
+
+		telegramClient := &BaseClient{
+			client: telegramHttpClient,
+			url:    "temp"}
+
+		// Act
+		updates, err := telegramClient.GetUpdatesWithContext(context.Background(), &dto.GetUpdatesRequest{
+			Offset:  10,
+			Timeout: 30,
+		})
+
+		// Assert
+
+		assert.Nil(t, err)
+
+		assert.EqualValues(t, map[string]interface{}{
+			"offset":  float64(10),
+			"timeout": float64(30),
+		}, sentBody)
+
+		assert.Equal(t, 2, len(updates))
+
+		assert.Equal(t, 10, updates[0].UpdateId)
+
+		assert.Equal(t, "/fact", updates[0].Message.Text)
+
+		assert.Equal(t, 11, updates[1].UpdateId)
+
+		assert.Equal(t, "/joke", updates[1].EditedMessage.Text)
+	})
+
+	t.Run("Conflicting webhook", func(t *testing.T) {
+
+		// Arrange
+		telegramHttpClient := &mocks.MockHttpClient{
+			DoFunc: func(*http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: 409,
+					Body:       ioutil.NopCloser(bytes.NewReader([]byte("{\"ok\": false,\"error_code\": 409,\"description\": \"Conflict: can't use getUpdates method while webhook is active\"}"))),
+				}, nil
+			},
+		}
+
+		telegramClient := &BaseClient{
+			client: telegramHttpClient,
+			url:    "temp"}
+
+		// Act
+		updates, err := telegramClient.GetUpdatesWithContext(context.Background(), &dto.GetUpdatesRequest{})
+
+		// Assert
+
+		assert.Nil(t, updates)
+
+		var apiError *TelegramAPIError
+
+		assert.True(t, errors.As(err, &apiError))
+
+		assert.Equal(t, 409, apiError.Code)
+	})
+}
@@ -0,0 +1,82 @@
+package restclient
+
+import (
+	"context"
+	"log"
+	"my-first-telegram-bot/telegram-handler/dto"
+	"net/http"
+)
+
+// TelegramApiClient calls the Telegram API methods that manage a bot, as opposed
+// to the ones it uses to chat. Each call goes through a BaseClient pointed at the method.
+type TelegramApiClient struct {
+	client  HttpClient
+	address string
+	token   string
+}
+
+// NewTelegramApiClient creates a client managing the bot owning the token, through the
+// Telegram API at the address, or DefaultTelegramApiAddress when it is empty.
+func NewTelegramApiClient(address string, token string) *TelegramApiClient {
+	return &TelegramApiClient{
+		client:  &http.Client{Timeout: TelegramApiTimeout},
+		address: address,
+		token:   token,
+	}
+}
+
+func (tc *TelegramApiClient) method(name string) *BaseClient {
+	return NewBaseClient(tc.client, telegramMethodUrl(tc.address, tc.token, name))
+}
+
+// SetWebhookWithContext asks Telegram to deliver the updates of the bot to the webhook.
+func (tc *TelegramApiClient) SetWebhookWithContext(ctx context.Context, request *dto.SetWebhookRequest) error {
+
+	log.Printf("Setting the webhook to %s", request.Url)
+
+	return callMethod(ctx, tc.method("setWebhook"), request, nil)
+}
+
+// GetWebhookInfoWithContext tells where Telegram delivers the updates of the bot to, and how that goes.
+func (tc *TelegramApiClient) GetWebhookInfoWithContext(ctx context.Context) (*dto.WebhookInfo, error) {
+
+	info := &dto.WebhookInfo{}
+
+	if err := callMethod(ctx, tc.method("getWebhookInfo"), struct{}{}, info); err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// DeleteWebhookWithContext stops the webhook delivery, so the updates can be fetched with getUpdates.
+func (tc *TelegramApiClient) DeleteWebhookWithContext(ctx context.Context, request *dto.DeleteWebhookRequest) error {
+
+	log.Printf("Deleting the webhook")
+
+	return callMethod(ctx, tc.method("deleteWebhook"), request, nil)
+}
+
+// SetMyCommandsWithContext sets the command menu Telegram shows to the users of a scope and language.
+func (tc *TelegramApiClient) SetMyCommandsWithContext(ctx context.Context, request *dto.SetMyCommandsRequest) error {
+
+	return callMethod(ctx, tc.method("setMyCommands"), request, nil)
+}
+
+// GetMeWithContext tells who the bot owning the token is, including its username.
+func (tc *TelegramApiClient) GetMeWithContext(ctx context.Context) (*dto.User, error) {
+
+	user := &dto.User{}
+
+	if err := callMethod(ctx, tc.method("getMe"), struct{}{}, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// AnswerCallbackQueryWithContext tells Telegram a button press was handled, so the button stops spinning.
+func (tc *TelegramApiClient) AnswerCallbackQueryWithContext(ctx context.Context, request *dto.AnswerCallbackQueryRequest) error {
+
+	return callMethod(ctx, tc.method("answerCallbackQuery"), request, nil)
+}
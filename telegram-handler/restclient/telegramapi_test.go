@@ -0,0 +1,266 @@
+package restclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"my-first-telegram-bot/telegram-handler/dto"
+	"my-first-telegram-bot/telegram-handler/utils/mocks"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingTelegramApi answers every method call with the response, recording the calls.
+func recordingTelegramApi(t *testing.T, rawResponse string, paths *[]string, bodies *[]map[string]interface{}) *mocks.MockHttpClient {
+	return &mocks.MockHttpClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+
+			var body map[string]interface{}
+
+			if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+				t.Fatal("Can't run test scenario")
+			}
+
+			*paths = append(*paths, req.URL.Path)
+			*bodies = append(*bodies, body)
+
+			return &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(bytes.NewReader([]byte(rawResponse))),
+			}, nil
+		},
+	}
+}
+
+func TestWebhookMethods(t *testing.T) {
+
+	t.Run("Set webhook", func(t *testing.T) {
+
+		var (
+			paths  []string
+			bodies []map[string]interface{}
+		)
+
+		// Arrange
+		telegramApiClient := &TelegramApiClient{
+			client: recordingTelegramApi(t, "{\"ok\": true,\"result\": true,\"description\": \"Webhook was set\"}", &paths, &bodies),
+			token:  "token",
+		}
+
+		// Act
+		err := telegramApiClient.SetWebhookWithContext(context.Background(), &dto.SetWebhookRequest{
+			Url:            "https://example.com/telegram",
+			SecretToken:    "s3cr3t",
+			AllowedUpdates: []string{"message", "callback_query"},
+			MaxConnections: 10,
+		})
+
+		// Assert
+
+		assert.Nil(t, err)
+
+		assert.Equal(t, []string{"/bottoken/setWebhook"}, paths)
+
+		assert.EqualValues(t, map[string]interface{}{
+			"url":             "https://example.com/telegram",
+			"secret_token":    "s3cr3t",
+			"allowed_updates": []interface{}{"message", "callback_query"},
+			"max_connections": float64(10),
+		}, bodies[0])
+	})
+
+	t.Run("Get webhook info", func(t *testing.T) {
+
+		var (
+			paths  []string
+			bodies []map[string]interface{}
+		)
+
+		// Arrange
+		telegramApiClient := &TelegramApiClient{
+			client: recordingTelegramApi(t, "{\"ok\": true,\"result\": {\"url\": \"https://example.com/telegram\",\"has_custom_certificate\": false,\"pending_update_count\": 3,\"last_error_date\": 1615076796,\"last_error_message\": \"Wrong response from the webhook: 500 Internal Server Error\",\"max_connections\": 40}}", &paths, &bodies),
+			token:  "token",
+		}
+
+		// Act
+		info, err := telegramApiClient.GetWebhookInfoWithContext(context.Background())
+
+		// Assert
+
+		assert.Nil(t, err)
+
+		assert.Equal(t, []string{"/bottoken/getWebhookInfo"}, paths)
+
+		assert.Equal(t, "https://example.com/telegram", info.Url)
+
+		assert.Equal(t, 3, info.PendingUpdateCount)
+
+		assert.Equal(t, 1615076796, info.LastErrorDate)
+
+		assert.Equal(t, "Wrong response from the webhook: 500 Internal Server Error", info.LastErrorMessage)
+
+		assert.Equal(t, 40, info.MaxConnections)
+	})
+
+	t.Run("Delete webhook", func(t *testing.T) {
+
+		var (
+			paths  []string
+			bodies []map[string]interface{}
+		)
+
+		// Arrange
+		telegramApiClient := &TelegramApiClient{
+			client: recordingTelegramApi(t, "{\"ok\": true,\"result\": true}", &paths, &bodies),
+			token:  "token",
+		}
+
+		// Act
+		err := telegramApiClient.DeleteWebhookWithContext(context.Background(), &dto.DeleteWebhookRequest{DropPendingUpdates: true})
+
+		// Assert
+
+		assert.Nil(t, err)
+
+		assert.Equal(t, []string{"/bottoken/deleteWebhook"}, paths)
+
+		assert.EqualValues(t, map[string]interface{}{"drop_pending_updates": true}, bodies[0])
+	})
+}
+
+func TestSetMyCommands(t *testing.T) {
+
+	t.Run("Scoped menu in a language", func(t *testing.T) {
+
+		var (
+			paths  []string
+			bodies []map[string]interface{}
+		)
+
+		// Arrange
+		telegramApiClient := &TelegramApiClient{
+			client: recordingTelegramApi(t, "{\"ok\": true,\"result\": true}", &paths, &bodies),
+			token:  "token",
+		}
+
+		// Act
+		err := telegramApiClient.SetMyCommandsWithContext(context.Background(), &dto.SetMyCommandsRequest{
+			Commands:     []dto.BotCommand{{Command: "fact", Description: "Eine zufällige nutzlose Tatsache"}},
+			Scope:        &dto.BotCommandScope{Type: dto.BotCommandScopeAllPrivateChats},
+			LanguageCode: "de",
+		})
+
+		// Assert
+
+		assert.Nil(t, err)
+
+		assert.Equal(t, []string{"/bottoken/setMyCommands"}, paths)
+
+		assert.EqualValues(t, map[string]interface{}{
+			"commands":      []interface{}{map[string]interface{}{"command": "fact", "description": "Eine zufällige nutzlose Tatsache"}},
+			"scope":         map[string]interface{}{"type": "all_private_chats"},
+			"language_code": "de",
+		}, bodies[0])
+	})
+}
+
+func TestAnswerCallbackQuery(t *testing.T) {
+
+	t.Run("Answer with a notification", func(t *testing.T) {
+
+		var (
+			paths  []string
+			bodies []map[string]interface{}
+		)
+
+		// Arrange
+		telegramApiClient := &TelegramApiClient{
+			client: recordingTelegramApi(t, "{\"ok\": true,\"result\": true}", &paths, &bodies),
+			token:  "token",
+		}
+
+		// Act
+		err := telegramApiClient.AnswerCallbackQueryWithContext(context.Background(), &dto.AnswerCallbackQueryRequest{
+			CallbackQueryId: "4382bfdwdsb323b2d9",
+			Text:            "Too late",
+		})
+
+		// Assert
+
+		assert.Nil(t, err)
+
+		assert.Equal(t, []string{"/bottoken/answerCallbackQuery"}, paths)
+
+		assert.EqualValues(t, map[string]interface{}{
+			"callback_query_id": "4382bfdwdsb323b2d9",
+			"text":              "Too late",
+		}, bodies[0])
+	})
+}
+
+func TestGetMe(t *testing.T) {
+
+	t.Run("Bot username", func(t *testing.T) {
+
+		var (
+			paths  []string
+			bodies []map[string]interface{}
+		)
+
+		// Arrange
+		telegramApiClient := &TelegramApiClient{
+			client: recordingTelegramApi(t, "{\"ok\": true,\"result\": {\"id\": 7,\"is_bot\": true,\"first_name\": \"Daily facts\",\"username\": \"MyDailyFactBot\"}}", &paths, &bodies),
+			token:  "token",
+		}
+
+		// Act
+		user, err := telegramApiClient.GetMeWithContext(context.Background())
+
+		// Assert
+
+		assert.Nil(t, err)
+
+		assert.Equal(t, []string{"/bottoken/getMe"}, paths)
+
+		assert.Equal(t, "MyDailyFactBot", user.Username)
+
+		assert.True(t, user.IsBot)
+	})
+}
+
+func TestTelegramApiAddress(t *testing.T) {
+
+	scenarios := []struct {
+		name        string
+		address     string
+		expectedUrl string
+	}{
+		{
+			name:        "Default address",
+			address:     "",
+			expectedUrl: "https://api.telegram.org/bottoken/sendMessage",
+		},
+		{
+			name:        "Fake Telegram server",
+			address:     "http://localhost:8081/bot",
+			expectedUrl: "http://localhost:8081/bottoken/sendMessage",
+		},
+	}
+
+	for _, scenario := range scenarios {
+
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+
+			// Act
+			client := NewTelegramClient(scenario.address, "token")
+
+			// Assert
+			assert.Equal(t, scenario.expectedUrl, client.url)
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"log"
+	"my-first-telegram-bot/telegram-handler/bot"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	DefaultAddress     = ":8080"
+	DefaultWebhookPath = "/telegram"
+
+	// DefaultRequestTimeout bounds the handling of each webhook request, like the Lambda timeout does.
+	DefaultRequestTimeout = 5 * time.Second
+
+	// DefaultShutdownTimeout is how long requests in flight are given to finish on shutdown.
+	DefaultShutdownTimeout = 10 * time.Second
+
+	// MaxBodySize is the largest webhook request body accepted.
+	MaxBodySize int64 = 1 << 20
+)
+
+// WebhookHandler processes the webhook requests the server receives.
+type WebhookHandler interface {
+	HandleWebhook(ctx context.Context, request *bot.WebhookRequest) *bot.Response
+}
+
+// Config holds the settings of the HTTP server.
+type Config struct {
+	Address string
+	// WebhookPath is where Telegram posts the updates to, optionally followed by a path token.
+	// Leading and trailing slashes don't matter, "/" takes the updates at the root.
+	WebhookPath     string
+	RequestTimeout  time.Duration
+	ShutdownTimeout time.Duration
+	// DrainDelay keeps the server serving, though not ready, for a while before shutting
+	// down, so load balancers polling /readyz stop sending requests first.
+	DrainDelay time.Duration
+}
+
+// ConfigFromEnv reads the server configuration from the environment, falling back to the defaults.
+func ConfigFromEnv() Config {
+
+	config := Config{
+		Address:         DefaultAddress,
+		WebhookPath:     DefaultWebhookPath,
+		RequestTimeout:  DefaultRequestTimeout,
+		ShutdownTimeout: DefaultShutdownTimeout,
+	}
+
+	if address := os.Getenv("LISTEN_ADDRESS"); address != "" {
+		config.Address = address
+	}
+
+	if webhookPath := os.Getenv("WEBHOOK_PATH"); webhookPath != "" {
+		config.WebhookPath = webhookPath
+	}
+
+	if drainDelay, err := time.ParseDuration(os.Getenv("SHUTDOWN_DRAIN_DELAY")); err == nil {
+		config.DrainDelay = drainDelay
+	}
+
+	return config
+}
+
+// Server serves the Telegram webhook over plain HTTP, along with the /healthz
+// and /readyz endpoints used by container orchestrators.
+type Server struct {
+	config  Config
+	handler WebhookHandler
+	ready   int32
+}
+
+func NewServer(handler WebhookHandler, config Config) *Server {
+	return &Server{
+		config:  config,
+		handler: handler,
+	}
+}
+
+// Handler routes the requests to the webhook and the health endpoints.
+func (s *Server) Handler() http.Handler {
+
+	webhookPath := strings.Trim(s.config.WebhookPath, "/")
+
+	mux := http.NewServeMux()
+
+	if webhookPath == "" {
+		mux.HandleFunc("/", s.handleWebhook)
+	} else {
+		mux.HandleFunc("/"+webhookPath, s.handleWebhook)
+		mux.HandleFunc("/"+webhookPath+"/", s.handleWebhook)
+	}
+
+	mux.HandleFunc("/healthz", s.handleHealth)
+	mux.HandleFunc("/readyz", s.handleReadiness)
+
+	return mux
+}
+
+// Run listens on the configured address and serves until the context is done.
+func (s *Server) Run(ctx context.Context) error {
+
+	listener, err := net.Listen("tcp", s.config.Address)
+
+	if err != nil {
+		return err
+	}
+
+	return s.Serve(ctx, listener)
+}
+
+// Serve serves on the listener until the context is done, then shuts down
+// gracefully: the server stops being ready, waits for the drain delay and
+// gives the requests in flight the shutdown timeout to finish.
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+
+	httpServer := &http.Server{Handler: s.Handler()}
+
+	serveErr := make(chan error, 1)
+
+	go func() {
+		serveErr <- httpServer.Serve(listener)
+	}()
+
+	atomic.StoreInt32(&s.ready, 1)
+
+	log.Printf("Listening on %s", listener.Addr())
+
+	select {
+	case err := <-serveErr:
+		atomic.StoreInt32(&s.ready, 0)
+
+		return err
+	case <-ctx.Done():
+	}
+
+	atomic.StoreInt32(&s.ready, 0)
+
+	log.Printf("Shutting down")
+
+	if s.config.DrainDelay > 0 {
+		time.Sleep(s.config.DrainDelay)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.config.ShutdownTimeout)
+	defer cancel()
+
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, MaxBodySize))
+
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.config.RequestTimeout)
+	defer cancel()
+
+	response := s.handler.HandleWebhook(ctx, &bot.WebhookRequest{
+		Path:    r.URL.Path,
+		Headers: requestHeaders(r),
+		Body:    string(body),
+	})
+
+	for name, value := range response.Headers {
+		w.Header().Set(name, value)
+	}
+
+	w.WriteHeader(response.StatusCode)
+	w.Write([]byte(response.Body))
+}
+
+// handleHealth tells the server is alive for as long as it answers.
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+
+	w.Write([]byte("ok"))
+}
+
+// handleReadiness tells whether the server takes webhook requests, which stops once it is shutting down.
+func (s *Server) handleReadiness(w http.ResponseWriter, r *http.Request) {
+
+	if !s.isReady() {
+		http.Error(w, "shutting down", http.StatusServiceUnavailable)
+
+		return
+	}
+
+	w.Write([]byte("ok"))
+}
+
+func (s *Server) isReady() bool {
+	return atomic.LoadInt32(&s.ready) == 1
+}
+
+// requestHeaders keeps the first value of each header of the request.
+func requestHeaders(r *http.Request) map[string]string {
+
+	headers := make(map[string]string, len(r.Header))
+
+	for name, values := range r.Header {
+		if len(values) > 0 {
+			headers[name] = values[0]
+		}
+	}
+
+	return headers
+}
@@ -0,0 +1,292 @@
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"my-first-telegram-bot/telegram-handler/bot"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingWebhookHandler struct {
+	requests []*bot.WebhookRequest
+	deadline time.Time
+	response *bot.Response
+	started  chan struct{}
+	release  chan struct{}
+}
+
+func (handler *recordingWebhookHandler) HandleWebhook(ctx context.Context, request *bot.WebhookRequest) *bot.Response {
+
+	handler.requests = append(handler.requests, request)
+	handler.deadline, _ = ctx.Deadline()
+
+	if handler.started != nil {
+		close(handler.started)
+		<-handler.release
+	}
+
+	return handler.response
+}
+
+func testConfig() Config {
+	return Config{
+		WebhookPath:     DefaultWebhookPath,
+		RequestTimeout:  DefaultRequestTimeout,
+		ShutdownTimeout: time.Second,
+	}
+}
+
+func TestWebhook(t *testing.T) {
+
+	t.Run("Webhook requests are handed to the bot", func(t *testing.T) {
+
+		// Arrange
+		handler := &recordingWebhookHandler{response: &bot.Response{
+			StatusCode: 200,
+			Headers:    map[string]string{"Content-Type": "application/json"},
+			Body:       "{\"method\":\"sendMessage\"}",
+		}}
+
+		request := httptest.NewRequest(http.MethodPost, "/telegram/pathToken", strings.NewReader("{\"update_id\": 1}"))
+
+		request.Header.Set("X-Telegram-Bot-Api-Secret-Token", "s3cr3t")
+
+		recorder := httptest.NewRecorder()
+
+		// Act
+		NewServer(handler, testConfig()).Handler().ServeHTTP(recorder, request)
+
+		// Assert
+
+		assert.Equal(t, 200, recorder.Code)
+
+		assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+		assert.Equal(t, "{\"method\":\"sendMessage\"}", recorder.Body.String())
+
+		assert.Equal(t, 1, len(handler.requests))
+
+		assert.Equal(t, "/telegram/pathToken", handler.requests[0].Path)
+
+		assert.Equal(t, "{\"update_id\": 1}", handler.requests[0].Body)
+
+		assert.Equal(t, "s3cr3t", handler.requests[0].Header(bot.SecretTokenHeader))
+
+		assert.WithinDuration(t, time.Now().Add(DefaultRequestTimeout), handler.deadline, time.Second)
+	})
+
+	t.Run("Webhook paths are served whatever their slashes", func(t *testing.T) {
+
+		scenarios := []struct {
+			webhookPath string
+			requestPath string
+		}{
+			{webhookPath: "/", requestPath: "/"},
+			{webhookPath: "/", requestPath: "/pathToken"},
+			{webhookPath: "telegram", requestPath: "/telegram"},
+			{webhookPath: "/telegram/", requestPath: "/telegram/pathToken"},
+		}
+
+		for _, scenario := range scenarios {
+
+			// Arrange
+			handler := &recordingWebhookHandler{response: &bot.Response{StatusCode: 200}}
+
+			config := testConfig()
+			config.WebhookPath = scenario.webhookPath
+
+			recorder := httptest.NewRecorder()
+
+			// Act
+			NewServer(handler, config).Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, scenario.requestPath, strings.NewReader("{}")))
+
+			// Assert
+
+			assert.Equal(t, 200, recorder.Code, scenario.webhookPath)
+
+			assert.Equal(t, 1, len(handler.requests), scenario.webhookPath)
+		}
+	})
+
+	t.Run("Rejections are passed on", func(t *testing.T) {
+
+		// Arrange
+		handler := &recordingWebhookHandler{response: &bot.Response{StatusCode: 401, Body: bot.Unauthorized}}
+
+		recorder := httptest.NewRecorder()
+
+		// Act
+		NewServer(handler, testConfig()).Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/telegram", strings.NewReader("{}")))
+
+		// Assert
+
+		assert.Equal(t, 401, recorder.Code)
+
+		assert.Equal(t, bot.Unauthorized, recorder.Body.String())
+	})
+
+	t.Run("Only POST is allowed", func(t *testing.T) {
+
+		// Arrange
+		handler := &recordingWebhookHandler{}
+
+		recorder := httptest.NewRecorder()
+
+		// Act
+		NewServer(handler, testConfig()).Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/telegram", nil))
+
+		// Assert
+
+		assert.Equal(t, 405, recorder.Code)
+
+		assert.Equal(t, 0, len(handler.requests))
+	})
+
+	t.Run("Oversized bodies are refused", func(t *testing.T) {
+
+		// Arrange
+		handler := &recordingWebhookHandler{}
+
+		recorder := httptest.NewRecorder()
+
+		body := strings.NewReader(strings.Repeat("a", int(MaxBodySize)+1))
+
+		// Act
+		NewServer(handler, testConfig()).Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/telegram", body))
+
+		// Assert
+
+		assert.Equal(t, 413, recorder.Code)
+
+		assert.Equal(t, 0, len(handler.requests))
+	})
+}
+
+func TestServe(t *testing.T) {
+
+	t.Run("Requests in flight finish on shutdown", func(t *testing.T) {
+
+		// Arrange
+		handler := &recordingWebhookHandler{
+			response: &bot.Response{StatusCode: 200, Body: "done"},
+			started:  make(chan struct{}),
+			release:  make(chan struct{}),
+		}
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+		if err != nil {
+			t.Fatal("Can't run test scenario")
+		}
+
+		address := "http://" + listener.Addr().String()
+
+		webhookServer := NewServer(handler, testConfig())
+
+		ctx, cancel := context.WithCancel(context.Background())
+
+		served := make(chan error, 1)
+
+		// Act
+		go func() {
+			served <- webhookServer.Serve(ctx, listener)
+		}()
+
+		client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+
+		ready := waitForReadiness(client, address)
+
+		responses := make(chan string, 1)
+
+		go func() {
+			response, err := client.Post(address+"/telegram", "application/json", strings.NewReader("{}"))
+
+			if err != nil {
+				responses <- err.Error()
+				return
+			}
+
+			defer response.Body.Close()
+
+			body, _ := ioutil.ReadAll(response.Body)
+
+			responses <- string(body)
+		}()
+
+		<-handler.started
+
+		cancel()
+
+		notReadyWhileShuttingDown := assert.Eventually(t, func() bool {
+			return !webhookServer.isReady()
+		}, readinessTimeout, time.Millisecond)
+
+		close(handler.release)
+
+		// Assert
+
+		assert.True(t, ready)
+
+		assert.True(t, notReadyWhileShuttingDown)
+
+		assert.Equal(t, "done", <-responses)
+
+		assert.Nil(t, <-served)
+	})
+}
+
+func TestHealth(t *testing.T) {
+
+	t.Run("Health and readiness", func(t *testing.T) {
+
+		// Arrange
+		webhookServer := NewServer(&recordingWebhookHandler{}, testConfig())
+
+		health := httptest.NewRecorder()
+
+		readiness := httptest.NewRecorder()
+
+		// Act
+		webhookServer.Handler().ServeHTTP(health, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+		webhookServer.Handler().ServeHTTP(readiness, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+		// Assert
+
+		assert.Equal(t, 200, health.Code)
+
+		assert.Equal(t, 503, readiness.Code)
+	})
+}
+
+// readinessTimeout bounds the wait for the server to change readiness, generously
+// so that loaded machines don't fail the tests.
+const readinessTimeout = 5 * time.Second
+
+// waitForReadiness polls /readyz until the server is ready, or gives up after readinessTimeout.
+func waitForReadiness(client *http.Client, address string) bool {
+
+	for deadline := time.Now().Add(readinessTimeout); time.Now().Before(deadline); {
+
+		response, err := client.Get(address + "/readyz")
+
+		if err == nil {
+			response.Body.Close()
+
+			if response.StatusCode == 200 {
+				return true
+			}
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return false
+}
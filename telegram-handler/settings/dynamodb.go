@@ -0,0 +1,102 @@
+package settings
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// ChatIdAttribute is the partition key of the chat settings table, a number.
+const ChatIdAttribute = "chat_id"
+
+var ErrNoRegion = errors.New("no AWS region configured")
+
+// DynamoDBStore keeps the settings in a DynamoDB table, one item per chat, so
+// every Lambda instance shares them.
+type DynamoDBStore struct {
+	client dynamodbiface.DynamoDBAPI
+	table  string
+}
+
+func NewDynamoDBStore(client dynamodbiface.DynamoDBAPI, table string) *DynamoDBStore {
+	return &DynamoDBStore{
+		client: client,
+		table:  table,
+	}
+}
+
+// NewDynamoDBStoreFromEnv creates a store for the table using the credentials and region
+// of the environment. A non empty endpoint points it elsewhere, like to DynamoDB Local.
+// Without a region every request would fail, so it is an error right away.
+func NewDynamoDBStoreFromEnv(table string, endpoint string) (*DynamoDBStore, error) {
+
+	config := aws.NewConfig()
+
+	if endpoint != "" {
+		config = config.WithEndpoint(endpoint)
+	}
+
+	awsSession, err := session.NewSession(config)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if aws.StringValue(awsSession.Config.Region) == "" {
+		return nil, ErrNoRegion
+	}
+
+	return NewDynamoDBStore(dynamodb.New(awsSession), table), nil
+}
+
+func (s *DynamoDBStore) Get(ctx context.Context, chatId int) (*ChatSettings, error) {
+
+	output, err := s.client.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.table),
+		Key:       chatIdKey(chatId),
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	settings := &ChatSettings{}
+
+	if err := dynamodbattribute.UnmarshalMap(output.Item, settings); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+func (s *DynamoDBStore) Put(ctx context.Context, chatId int, settings *ChatSettings) error {
+
+	item, err := dynamodbattribute.MarshalMap(settings)
+
+	if err != nil {
+		return err
+	}
+
+	for name, value := range chatIdKey(chatId) {
+		item[name] = value
+	}
+
+	_, err = s.client.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.table),
+		Item:      item,
+	})
+
+	return err
+}
+
+func chatIdKey(chatId int) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		ChatIdAttribute: {N: aws.String(strconv.Itoa(chatId))},
+	}
+}
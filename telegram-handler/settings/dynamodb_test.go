@@ -0,0 +1,121 @@
+package settings
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeDynamoDB answers GetItem and PutItem like DynamoDB Local does, keeping the
+// items by table and chat id.
+type fakeDynamoDB struct {
+	mu    sync.Mutex
+	items map[string]map[string]interface{}
+}
+
+func (f *fakeDynamoDB) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	var input struct {
+		TableName string
+		Key       map[string]map[string]string
+		Item      map[string]interface{}
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+
+	switch strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "DynamoDB_20120810.") {
+
+	case "GetItem":
+		item, found := f.items[input.TableName+"/"+input.Key[ChatIdAttribute]["N"]]
+
+		if !found {
+			w.Write([]byte("{}"))
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{"Item": item})
+
+	case "PutItem":
+		chatId := input.Item[ChatIdAttribute].(map[string]interface{})["N"].(string)
+
+		f.items[input.TableName+"/"+chatId] = input.Item
+
+		w.Write([]byte("{}"))
+
+	default:
+		http.Error(w, "Unsupported operation", http.StatusBadRequest)
+	}
+}
+
+func TestDynamoDBStore(t *testing.T) {
+
+	t.Run("Settings are kept in one item per chat", func(t *testing.T) {
+
+		// Arrange
+		fake := &fakeDynamoDB{items: map[string]map[string]interface{}{}}
+
+		server := httptest.NewServer(fake)
+		defer server.Close()
+
+		awsSession, err := session.NewSession(aws.NewConfig().
+			WithEndpoint(server.URL).
+			WithRegion("eu-west-1").
+			WithCredentials(credentials.NewStaticCredentials("local", "local", "")))
+
+		if err != nil {
+			t.Fatal("Can't run test scenario")
+		}
+
+		store := NewDynamoDBStore(dynamodb.New(awsSession), "ChatSettings")
+
+		chatSettings := &ChatSettings{
+			Language:       "de",
+			JokeCategories: []string{"pun"},
+			AllowExplicit:  true,
+		}
+
+		// Act
+		putErr := store.Put(context.Background(), -1001, chatSettings)
+
+		saved, err := store.Get(context.Background(), -1001)
+
+		missing, missingErr := store.Get(context.Background(), 1234)
+
+		// Assert
+
+		assert.Nil(t, putErr)
+
+		assert.Nil(t, err)
+
+		assert.Equal(t, chatSettings, saved)
+
+		assert.Nil(t, missingErr)
+
+		assert.Equal(t, &ChatSettings{}, missing)
+
+		assert.Equal(t, map[string]interface{}{
+			"chat_id":         map[string]interface{}{"N": "-1001"},
+			"language":        map[string]interface{}{"S": "de"},
+			"joke_categories": map[string]interface{}{"L": []interface{}{map[string]interface{}{"S": "pun"}}},
+			"allow_explicit":  map[string]interface{}{"BOOL": true},
+		}, fake.items["ChatSettings/-1001"])
+	})
+}
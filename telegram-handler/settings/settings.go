@@ -0,0 +1,133 @@
+package settings
+
+import (
+	"context"
+	"my-first-telegram-bot/telegram-handler/internal/jsonfile"
+	"strconv"
+	"sync"
+)
+
+// ChatSettings are the preferences of a chat. The zero value stands for the defaults.
+type ChatSettings struct {
+	// Language of the facts and jokes, empty for the default language of each source.
+	Language string `json:"language,omitempty"`
+	// JokeCategories are picked from when a joke is asked for without a category.
+	JokeCategories []string `json:"joke_categories,omitempty"`
+	// AllowExplicit turns the explicit content filter of the jokes off.
+	AllowExplicit bool `json:"allow_explicit,omitempty"`
+}
+
+// ChatSettingsStore keeps the settings of every chat.
+type ChatSettingsStore interface {
+	// Get returns the settings of the chat, the zero value when none were saved.
+	Get(ctx context.Context, chatId int) (*ChatSettings, error)
+	Put(ctx context.Context, chatId int, settings *ChatSettings) error
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of the context carrying the settings of the chat a command runs for.
+func NewContext(ctx context.Context, settings *ChatSettings) context.Context {
+	return context.WithValue(ctx, contextKey{}, settings)
+}
+
+// FromContext returns the settings carried by the context, the zero value when there are none.
+func FromContext(ctx context.Context) *ChatSettings {
+
+	if settings, ok := ctx.Value(contextKey{}).(*ChatSettings); ok && settings != nil {
+		return settings
+	}
+
+	return &ChatSettings{}
+}
+
+// MemoryStore keeps the settings in memory, so they last as long as the process.
+type MemoryStore struct {
+	mu       sync.Mutex
+	settings map[int]ChatSettings
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		settings: map[int]ChatSettings{},
+	}
+}
+
+func (s *MemoryStore) Get(ctx context.Context, chatId int) (*ChatSettings, error) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	settings := s.settings[chatId]
+
+	return &settings, nil
+}
+
+func (s *MemoryStore) Put(ctx context.Context, chatId int, settings *ChatSettings) error {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.settings[chatId] = *settings
+
+	return nil
+}
+
+// FileStore keeps the settings in a JSON file, so they survive restarts during local runs.
+type FileStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewFileStore(path string) *FileStore {
+	return &FileStore{
+		path: path,
+	}
+}
+
+func (s *FileStore) Get(ctx context.Context, chatId int) (*ChatSettings, error) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, err := s.load()
+
+	if err != nil {
+		return nil, err
+	}
+
+	settings := stored[strconv.Itoa(chatId)]
+
+	return &settings, nil
+}
+
+func (s *FileStore) Put(ctx context.Context, chatId int, settings *ChatSettings) error {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, err := s.load()
+
+	if err != nil {
+		return err
+	}
+
+	stored[strconv.Itoa(chatId)] = *settings
+
+	return s.save(stored)
+}
+
+func (s *FileStore) load() (map[string]ChatSettings, error) {
+
+	stored := map[string]ChatSettings{}
+
+	if err := jsonfile.Load(s.path, &stored); err != nil {
+		return nil, err
+	}
+
+	return stored, nil
+}
+
+func (s *FileStore) save(stored map[string]ChatSettings) error {
+	return jsonfile.Save(s.path, stored)
+}
@@ -0,0 +1,122 @@
+package settings
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore(t *testing.T) {
+
+	t.Run("Settings are kept by chat", func(t *testing.T) {
+
+		// Arrange
+		store := NewMemoryStore()
+
+		// Act
+		putErr := store.Put(context.Background(), 1234, &ChatSettings{Language: "de"})
+
+		saved, err := store.Get(context.Background(), 1234)
+
+		missing, missingErr := store.Get(context.Background(), 5678)
+
+		// Assert
+
+		assert.Nil(t, putErr)
+
+		assert.Nil(t, err)
+
+		assert.Equal(t, &ChatSettings{Language: "de"}, saved)
+
+		assert.Nil(t, missingErr)
+
+		assert.Equal(t, &ChatSettings{}, missing)
+	})
+}
+
+func TestFileStore(t *testing.T) {
+
+	t.Run("Settings are remembered across instances", func(t *testing.T) {
+
+		// Arrange
+		directory, err := ioutil.TempDir("", "settings")
+
+		if err != nil {
+			t.Fatal("Can't run test scenario")
+		}
+
+		defer os.RemoveAll(directory)
+
+		path := filepath.Join(directory, "settings.json")
+
+		chatSettings := &ChatSettings{
+			Language:       "de",
+			JokeCategories: []string{"pun", "spooky"},
+			AllowExplicit:  true,
+		}
+
+		// Act
+		putErr := NewFileStore(path).Put(context.Background(), -1001, chatSettings)
+
+		saved, err := NewFileStore(path).Get(context.Background(), -1001)
+
+		missing, missingErr := NewFileStore(path).Get(context.Background(), 1234)
+
+		// Assert
+
+		assert.Nil(t, putErr)
+
+		assert.Nil(t, err)
+
+		assert.Equal(t, chatSettings, saved)
+
+		assert.Nil(t, missingErr)
+
+		assert.Equal(t, &ChatSettings{}, missing)
+	})
+
+	t.Run("Corrupted file", func(t *testing.T) {
+
+		// Arrange
+		file, err := ioutil.TempFile("", "settings")
+
+		if err != nil {
+			t.Fatal("Can't run test scenario")
+		}
+
+		defer os.Remove(file.Name())
+
+		file.WriteString("{batata")
+		file.Close()
+
+		// Act
+		_, getErr := NewFileStore(file.Name()).Get(context.Background(), 1234)
+
+		putErr := NewFileStore(file.Name()).Put(context.Background(), 1234, &ChatSettings{})
+
+		// Assert
+
+		assert.NotNil(t, getErr)
+
+		assert.NotNil(t, putErr)
+	})
+}
+
+func TestContext(t *testing.T) {
+
+	t.Run("Settings travel with the context", func(t *testing.T) {
+
+		// Act
+		ctx := NewContext(context.Background(), &ChatSettings{Language: "de"})
+
+		// Assert
+
+		assert.Equal(t, &ChatSettings{Language: "de"}, FromContext(ctx))
+
+		assert.Equal(t, &ChatSettings{}, FromContext(context.Background()))
+	})
+}
@@ -1,34 +1,100 @@
 package mocks
 
 import (
+	"context"
 	"my-first-telegram-bot/telegram-handler/dto"
 	"net/http"
+	"sync"
+	"time"
 )
 
-var (
-	ReturnGetFact      func() (*dto.GeneratedFact, error)
-	ReturnGetJoke      func() (*dto.GeneratedJoke, error)
-	ReturnPostResponse func(chatId int, text string) (string, error)
-)
-
+// MockBaseClient answers every client method with its own funcs, so each test, and
+// each bot of a test, can fake its own behaviour.
 type MockBaseClient struct {
+	mu sync.Mutex
+
+	GetFactFunc      func() (*dto.GeneratedFact, error)
+	GetJokeFunc      func() (*dto.GeneratedJoke, error)
+	PostResponseFunc func(chatId int, text string) (*dto.Message, error)
+	SendMessageFunc  func(message *dto.SendMessageRequest) (*dto.Message, error)
+
 	ReturnGetFactCallCount      int
 	ReturnGetJokeCallCount      int
 	ReturnPostResponseCallCount int
+	ReturnSendMessageCallCount  int
+
+	// JokeCategories are the categories the client advertises.
+	JokeCategories []string
+	LastFactQuery  *dto.FactQuery
+	LastJokeQuery  *dto.JokeQuery
 }
 
 func (mck *MockBaseClient) GetFact() (*dto.GeneratedFact, error) {
+	mck.mu.Lock()
 	mck.ReturnGetFactCallCount++
-	return ReturnGetFact()
+	mck.mu.Unlock()
+
+	return mck.GetFactFunc()
+}
+
+func (mck *MockBaseClient) GetFactWithContext(ctx context.Context) (*dto.GeneratedFact, error) {
+	return mck.GetFact()
+}
+
+func (mck *MockBaseClient) GetFactWithQuery(ctx context.Context, query *dto.FactQuery) (*dto.GeneratedFact, error) {
+	mck.mu.Lock()
+	mck.LastFactQuery = query
+	mck.mu.Unlock()
+
+	return mck.GetFact()
 }
 
 func (mck *MockBaseClient) GetJoke() (*dto.GeneratedJoke, error) {
+	mck.mu.Lock()
 	mck.ReturnGetJokeCallCount++
-	return ReturnGetJoke()
+	mck.mu.Unlock()
+
+	return mck.GetJokeFunc()
+}
+
+func (mck *MockBaseClient) GetJokeWithContext(ctx context.Context) (*dto.GeneratedJoke, error) {
+	return mck.GetJoke()
+}
+
+func (mck *MockBaseClient) GetJokeWithQuery(ctx context.Context, query *dto.JokeQuery) (*dto.GeneratedJoke, error) {
+	mck.mu.Lock()
+	mck.LastJokeQuery = query
+	mck.mu.Unlock()
+
+	return mck.GetJoke()
+}
+
+func (mck *MockBaseClient) Categories() []string {
+	return mck.JokeCategories
 }
-func (mck *MockBaseClient) PostResponse(chatId int, text string) (string, error) {
+
+func (mck *MockBaseClient) PostResponse(chatId int, text string) (*dto.Message, error) {
+	mck.mu.Lock()
 	mck.ReturnPostResponseCallCount++
-	return ReturnPostResponse(chatId, text)
+	mck.mu.Unlock()
+
+	return mck.PostResponseFunc(chatId, text)
+}
+
+func (mck *MockBaseClient) PostResponseWithContext(ctx context.Context, chatId int, text string) (*dto.Message, error) {
+	return mck.PostResponse(chatId, text)
+}
+
+func (mck *MockBaseClient) SendMessage(message *dto.SendMessageRequest) (*dto.Message, error) {
+	mck.mu.Lock()
+	mck.ReturnSendMessageCallCount++
+	mck.mu.Unlock()
+
+	return mck.SendMessageFunc(message)
+}
+
+func (mck *MockBaseClient) SendMessageWithContext(ctx context.Context, message *dto.SendMessageRequest) (*dto.Message, error) {
+	return mck.SendMessage(message)
 }
 
 type MockHttpClient struct {
@@ -39,3 +105,38 @@ func (mckHt *MockHttpClient) Do(req *http.Request) (*http.Response, error) {
 
 	return mckHt.DoFunc(req)
 }
+
+// MockClock is a fake clock whose Sleep moves time forward instantly and records how long it was asked to sleep.
+type MockClock struct {
+	mu      sync.Mutex
+	Current time.Time
+	Slept   []time.Duration
+}
+
+func (mckCl *MockClock) Now() time.Time {
+	mckCl.mu.Lock()
+	defer mckCl.mu.Unlock()
+
+	return mckCl.Current
+}
+
+func (mckCl *MockClock) Sleep(ctx context.Context, d time.Duration) error {
+	mckCl.mu.Lock()
+	defer mckCl.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	mckCl.Slept = append(mckCl.Slept, d)
+	mckCl.Current = mckCl.Current.Add(d)
+
+	return nil
+}
+
+func (mckCl *MockClock) Advance(d time.Duration) {
+	mckCl.mu.Lock()
+	defer mckCl.mu.Unlock()
+
+	mckCl.Current = mckCl.Current.Add(d)
+}